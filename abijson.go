@@ -0,0 +1,641 @@
+package multicall
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/holiman/uint256"
+)
+
+/*
+ * JSON encoding driven by ABI types, so values look the same whatever Go type they were decoded into:
+ *	- integers are decimal strings (any size, so nothing is lost to float64),
+ *	- addresses are checksummed,
+ *	- bytes and fixed bytes are 0x-prefixed hex,
+ *	- tuples are objects keyed by component name (or position, when unnamed), in ABI order,
+ *	- arrays and slices are JSON arrays.
+ *
+ * Decoding produces the same Go types go-ethereum's unpacker does, so decoded values can be packed or converted
+ * like freshly unpacked ones.
+ */
+func MarshalValueJSON(t abi.Type, value interface{}) ([]byte, error) {
+	encoded, err := encodeABIValue(t, reflect.ValueOf(value))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+// Decodes JSON written by `MarshalValueJSON()`.
+func UnmarshalValueJSON(t abi.Type, data []byte) (interface{}, error) {
+	decoded, err := decodeABIValue(t, data)
+	if err != nil {
+		return nil, err
+	}
+	return decoded.Interface(), nil
+}
+
+// Encodes a list of arguments (or outputs) as an object keyed by name, or by position for unnamed ones.
+func MarshalArgumentsJSON(args abi.Arguments, values []interface{}) ([]byte, error) {
+	encoded, err := encodeArguments(args, values)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+// Decodes JSON written by `MarshalArgumentsJSON()`, returning values in ABI order.
+func UnmarshalArgumentsJSON(args abi.Arguments, data []byte) ([]interface{}, error) {
+	fields, err := jsonFields(data, len(args))
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		raw, ok := fields[argumentKey(arg.Name, i)]
+		if !ok {
+			return nil, fmt.Errorf("missing value for %s", argumentKey(arg.Name, i))
+		}
+		decoded, err := decodeABIValue(arg.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", argumentKey(arg.Name, i), err)
+		}
+		values[i] = decoded.Interface()
+	}
+	return values, nil
+}
+
+type callJSON struct {
+	Target    common.Address  `json:"target"`
+	Method    string          `json:"method"`
+	Signature string          `json:"signature,omitempty"`
+	Args      json.RawMessage `json:"args,omitempty"`
+	Data      hexutil.Bytes   `json:"data,omitempty"`
+	Value     *big.Int        `json:"value,omitempty"`
+	From      *common.Address `json:"from,omitempty"`
+}
+
+/*
+ * Encodes the call as `{"target", "method", "signature", "args"}`, or `{"target", "method", "data"}` when its ABI
+ * isn't known, plus its `value` and `from` if it has them. `signature` is the full human-readable signature, with names and outputs, so that `UnmarshalJSON()`
+ * can rebuild the call without its ABI.
+ */
+func (md *MultiCallMetaData[T]) MarshalJSON() ([]byte, error) {
+	if md.Method == nil || len(md.Data) < 4 {
+		return json.Marshal(callJSON{Target: md.Address, Method: md.FunctionName, Data: md.Data, Value: md.Value, From: md.From})
+	}
+	args, err := md.Method.Inputs.Unpack(md.Data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("error unpacking arguments of %s: %s", md.FunctionName, err.Error())
+	}
+	encoded, err := MarshalArgumentsJSON(md.Method.Inputs, args)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(callJSON{Target: md.Address, Method: md.Method.Sig, Signature: methodSignature(*md.Method), Args: encoded, Value: md.Value, From: md.From})
+}
+
+/*
+ * Decodes a call encoded by `MarshalJSON()`. Its results are decoded into T from the signature's outputs, like
+ * `DescribeSig()` does. A call encoded from raw calldata has no ABI to decode its results with, so it fails
+ * when it's made (`MultiCallMetaData[[]byte]` gets the raw return data instead).
+ */
+func (md *MultiCallMetaData[T]) UnmarshalJSON(data []byte) error {
+	var decoded callJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Signature == "" {
+		if len(decoded.Args) > 0 {
+			return fmt.Errorf("%s: args need a signature to be encoded with", decoded.Method)
+		}
+		method := decoded.Method
+		*md = MultiCallMetaData[T]{
+			Address:      decoded.Target,
+			Data:         decoded.Data,
+			FunctionName: method,
+			Value:        decoded.Value,
+			From:         decoded.From,
+			Deserialize: func(b []byte) (*T, error) {
+				if raw, ok := any(&b).(*T); ok {
+					return raw, nil
+				}
+				return nil, fmt.Errorf("no ABI to decode %s with", method)
+			},
+		}
+		return nil
+	}
+
+	method, err := ParseSignature(decoded.Signature)
+	if err != nil {
+		return err
+	}
+	if method.Sig != decoded.Method {
+		return fmt.Errorf("signature %q doesn't match method %s", decoded.Signature, decoded.Method)
+	}
+	args := decoded.Args
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	values, err := UnmarshalArgumentsJSON(method.Inputs, args)
+	if err != nil {
+		return err
+	}
+	packed, err := method.Inputs.Pack(values...)
+	if err != nil {
+		return fmt.Errorf("error packing multicall: %s", err.Error())
+	}
+	outputs := method.Outputs
+	*md = MultiCallMetaData[T]{
+		Address:      decoded.Target,
+		Data:         append(append([]byte{}, method.ID...), packed...),
+		FunctionName: method.RawName,
+		Method:       method,
+		Value:        decoded.Value,
+		From:         decoded.From,
+		Deserialize: func(b []byte) (*T, error) {
+			return unpackOutputs[T](outputs, b)
+		},
+	}
+	return nil
+}
+
+// methodSignature writes m in the Solidity-style form `ParseSignature()` reads, keeping names and outputs.
+func methodSignature(m abi.Method) string {
+	signature := "function " + m.RawName + "(" + formatArguments(m.Inputs) + ")"
+	if m.StateMutability != "" {
+		signature += " " + m.StateMutability
+	}
+	if len(m.Outputs) > 0 {
+		signature += " returns (" + formatArguments(m.Outputs) + ")"
+	}
+	return signature
+}
+
+func formatArguments(args abi.Arguments) string {
+	params := make([]string, len(args))
+	for i, arg := range args {
+		params[i] = formatParam(arg.Type, arg.Name)
+	}
+	return strings.Join(params, ", ")
+}
+
+func formatParam(t abi.Type, name string) string {
+	if name == "" {
+		return formatType(t)
+	}
+	return formatType(t) + " " + name
+}
+
+func formatType(t abi.Type) string {
+	switch t.T {
+	case abi.TupleTy:
+		components := make([]string, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			components[i] = formatParam(*elem, t.TupleRawNames[i])
+		}
+		return "(" + strings.Join(components, ", ") + ")"
+	case abi.SliceTy:
+		return formatType(*t.Elem) + "[]"
+	case abi.ArrayTy:
+		return fmt.Sprintf("%s[%d]", formatType(*t.Elem), t.Size)
+	}
+	return t.String()
+}
+
+type resultJSON struct {
+	Ok     bool            `json:"ok"`
+	Value  json.RawMessage `json:"value,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Revert hexutil.Bytes   `json:"revert,omitempty"`
+}
+
+/*
+ * Encodes the result of `md` as `{"ok": true, "value": ...}` or `{"ok": false, "error": ..., "revert": ...}`,
+ * using the method's output types. Methods with several outputs are encoded as an object of outputs.
+ */
+func MarshalResultJSON[T any](md *MultiCallMetaData[T], res Result[T]) ([]byte, error) {
+	if !res.Ok() {
+		out := resultJSON{Error: res.Err().Error()}
+		var revert *RevertError
+		if errors.As(res.Err(), &revert) {
+			out.Revert = revert.Data
+		}
+		return json.Marshal(out)
+	}
+	if md.Method == nil {
+		return nil, fmt.Errorf("%s has no ABI to encode its result with", md.FunctionName)
+	}
+
+	outputs := md.Method.Outputs
+	var value interface{}
+	var err error
+	if len(outputs) == 1 {
+		value, err = encodeABIValue(outputs[0].Type, reflect.ValueOf(res.Value))
+	} else {
+		value, err = encodeTuple(outputs, reflect.ValueOf(res.Value))
+	}
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resultJSON{Ok: true, Value: encoded})
+}
+
+// Decodes JSON written by `MarshalResultJSON()`. Reverts are decoded again from their data.
+func UnmarshalResultJSON[T any](md *MultiCallMetaData[T], data []byte) (Result[T], error) {
+	var in resultJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return Result[T]{}, err
+	}
+	if !in.Ok {
+		if in.Revert != nil {
+			return failedResult[T](DecodeRevert(in.Revert)), nil
+		}
+		return failedResult[T](errors.New(in.Error)), nil
+	}
+	if md.Method == nil {
+		return Result[T]{}, fmt.Errorf("%s has no ABI to decode its result with", md.FunctionName)
+	}
+
+	outputs := md.Method.Outputs
+	out := new(T)
+	target := reflect.ValueOf(out).Elem()
+	if len(outputs) == 1 {
+		decoded, err := decodeABIValue(outputs[0].Type, in.Value)
+		if err != nil {
+			return Result[T]{}, err
+		}
+		if err := setConverted(target, decoded.Interface()); err != nil {
+			return Result[T]{}, err
+		}
+		return okResult(*out), nil
+	}
+
+	values, err := UnmarshalArgumentsJSON(outputs, in.Value)
+	if err != nil {
+		return Result[T]{}, err
+	}
+	if target.Type() == reflect.TypeOf([]interface{}{}) {
+		target.Set(reflect.ValueOf(values))
+		return okResult(*out), nil
+	}
+	if target.Kind() != reflect.Struct {
+		return Result[T]{}, fmt.Errorf("method returns %d values, which can't be stored in %s", len(values), target.Type())
+	}
+	if err := assignFields(target, argumentNames(outputs), values); err != nil {
+		return Result[T]{}, err
+	}
+	return okResult(*out), nil
+}
+
+// jsonObject is a JSON object that keeps its keys in ABI order.
+type jsonObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func argumentKey(name string, index int) string {
+	if name == "" {
+		return strconv.Itoa(index)
+	}
+	return name
+}
+
+func argumentNames(args abi.Arguments) []string {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = arg.Name
+	}
+	return names
+}
+
+func encodeArguments(args abi.Arguments, values []interface{}) (jsonObject, error) {
+	if len(args) != len(values) {
+		return jsonObject{}, fmt.Errorf("expected %d values, got %d", len(args), len(values))
+	}
+	out := jsonObject{}
+	for i, arg := range args {
+		encoded, err := encodeABIValue(arg.Type, reflect.ValueOf(values[i]))
+		if err != nil {
+			return jsonObject{}, fmt.Errorf("%s: %w", argumentKey(arg.Name, i), err)
+		}
+		out.keys = append(out.keys, argumentKey(arg.Name, i))
+		out.values = append(out.values, encoded)
+	}
+	return out, nil
+}
+
+// encodeTuple encodes the fields of a struct (or the entries of a map or []interface{}) as the given arguments.
+func encodeTuple(args abi.Arguments, v reflect.Value) (jsonObject, error) {
+	v = indirect(v)
+	values := make([]interface{}, len(args))
+	switch {
+	case !v.IsValid():
+		return jsonObject{}, errors.New("cannot encode nil as a tuple")
+	case v.Kind() == reflect.Struct:
+		fields := exportedFields(v.Type())
+		indices, ok := matchFields(v.Type(), fields, argumentNames(args))
+		if !ok {
+			if len(fields) < len(args) {
+				return jsonObject{}, fmt.Errorf("%s has %d exported fields, but the tuple has %d", v.Type(), len(fields), len(args))
+			}
+			indices = fields[:len(args)]
+		}
+		for i := range args {
+			values[i] = v.Field(indices[i]).Interface()
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		for i, arg := range args {
+			entry := v.MapIndex(reflect.ValueOf(argumentKey(arg.Name, i)).Convert(v.Type().Key()))
+			if !entry.IsValid() {
+				return jsonObject{}, fmt.Errorf("missing value for %s", argumentKey(arg.Name, i))
+			}
+			values[i] = entry.Interface()
+		}
+	case v.Kind() == reflect.Slice && v.Len() == len(args):
+		for i := range args {
+			values[i] = v.Index(i).Interface()
+		}
+	default:
+		return jsonObject{}, fmt.Errorf("cannot encode %s as a tuple", v.Type())
+	}
+	return encodeArguments(args, values)
+}
+
+func tupleArguments(t abi.Type) abi.Arguments {
+	args := make(abi.Arguments, len(t.TupleElems))
+	for i, elem := range t.TupleElems {
+		args[i] = abi.Argument{Name: t.TupleRawNames[i], Type: *elem}
+	}
+	return args
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.Kind() == reflect.Pointer && v.Type().Elem() == bigIntType {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func encodeABIValue(t abi.Type, v reflect.Value) (interface{}, error) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("cannot encode nil as %s", t.String())
+	}
+
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := toBigInt(v)
+		if err != nil {
+			return nil, err
+		}
+		return n.String(), nil
+	case abi.BoolTy:
+		if v.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("cannot encode %s as bool", v.Type())
+		}
+		return v.Bool(), nil
+	case abi.StringTy:
+		if v.Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot encode %s as string", v.Type())
+		}
+		return v.String(), nil
+	case abi.AddressTy:
+		if !v.Type().ConvertibleTo(reflect.TypeOf(common.Address{})) {
+			return nil, fmt.Errorf("cannot encode %s as address", v.Type())
+		}
+		return v.Convert(reflect.TypeOf(common.Address{})).Interface().(common.Address).Hex(), nil
+	case abi.BytesTy, abi.FixedBytesTy, abi.FunctionTy:
+		b, err := toBytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return hexutil.Encode(b), nil
+	case abi.SliceTy, abi.ArrayTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("cannot encode %s as %s", v.Type(), t.String())
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			elem, err := encodeABIValue(*t.Elem, v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			out[i] = elem
+		}
+		return out, nil
+	case abi.TupleTy:
+		return encodeTuple(tupleArguments(t), v)
+	}
+	return nil, fmt.Errorf("unsupported abi type %s", t.String())
+}
+
+func toBigInt(v reflect.Value) (*big.Int, error) {
+	switch {
+	case v.Type() == reflect.TypeOf(&big.Int{}):
+		if v.IsNil() {
+			return nil, errors.New("cannot encode a nil *big.Int")
+		}
+		return v.Interface().(*big.Int), nil
+	case v.Type() == bigIntType:
+		n := v.Interface().(big.Int)
+		return &n, nil
+	case v.Type() == reflect.TypeOf(uint256.Int{}):
+		n := v.Interface().(uint256.Int)
+		return n.ToBig(), nil
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		return big.NewInt(v.Interface().(time.Time).Unix()), nil
+	case v.CanInt():
+		return big.NewInt(v.Int()), nil
+	case v.CanUint():
+		return new(big.Int).SetUint64(v.Uint()), nil
+	}
+	return nil, fmt.Errorf("cannot encode %s as an integer", v.Type())
+}
+
+func toBytes(v reflect.Value) ([]byte, error) {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, fmt.Errorf("cannot encode %s as bytes", v.Type())
+	}
+	out := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(out), v)
+	return out, nil
+}
+
+// jsonFields reads an object keyed by argument name or position. Arrays are accepted as well, by position.
+func jsonFields(data []byte, count int) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err == nil {
+		return fields, nil
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("expected an object or an array, got %s", string(data))
+	}
+	if len(list) != count {
+		return nil, fmt.Errorf("expected %d values, got %d", count, len(list))
+	}
+	for i, raw := range list {
+		fields[strconv.Itoa(i)] = raw
+	}
+	return fields, nil
+}
+
+func decodeABIValue(t abi.Type, data json.RawMessage) (reflect.Value, error) {
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		return decodeInteger(t, data)
+	case abi.BoolTy:
+		var b bool
+		if err := json.Unmarshal(data, &b); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b), nil
+	case abi.StringTy:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(s), nil
+	case abi.AddressTy:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return reflect.Value{}, err
+		}
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("invalid address %q", s)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+	case abi.BytesTy:
+		var b hexutil.Bytes
+		if err := json.Unmarshal(data, &b); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf([]byte(b)), nil
+	case abi.FixedBytesTy, abi.FunctionTy:
+		var b hexutil.Bytes
+		if err := json.Unmarshal(data, &b); err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(t.GetType()).Elem()
+		if len(b) != out.Len() {
+			return reflect.Value{}, fmt.Errorf("expected %d bytes, got %d", out.Len(), len(b))
+		}
+		reflect.Copy(out, reflect.ValueOf([]byte(b)))
+		return out, nil
+	case abi.SliceTy, abi.ArrayTy:
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			return reflect.Value{}, err
+		}
+		var out reflect.Value
+		if t.T == abi.SliceTy {
+			out = reflect.MakeSlice(t.GetType(), len(list), len(list))
+		} else {
+			out = reflect.New(t.GetType()).Elem()
+			if len(list) != t.Size {
+				return reflect.Value{}, fmt.Errorf("expected %d elements, got %d", t.Size, len(list))
+			}
+		}
+		for i, raw := range list {
+			elem, err := decodeABIValue(*t.Elem, raw)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case abi.TupleTy:
+		values, err := UnmarshalArgumentsJSON(tupleArguments(t), data)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(t.GetType()).Elem()
+		for i, value := range values {
+			out.Field(i).Set(reflect.ValueOf(value))
+		}
+		return out, nil
+	}
+	return reflect.Value{}, fmt.Errorf("unsupported abi type %s", t.String())
+}
+
+// decodeInteger accepts decimal or 0x-prefixed strings as well as plain JSON numbers, and checks the type's range.
+func decodeInteger(t abi.Type, data json.RawMessage) (reflect.Value, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return reflect.Value{}, fmt.Errorf("expected an integer, got %s", string(data))
+		}
+		s = number.String()
+	}
+	// decimal, unless explicitly 0x-prefixed: a leading zero doesn't make "010" octal.
+	digits, base := strings.TrimPrefix(s, "-"), 10
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits, base = digits[2:], 16
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if ok && strings.HasPrefix(s, "-") {
+		n.Neg(n)
+	}
+	if !ok || strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return reflect.Value{}, fmt.Errorf("invalid integer %q", s)
+	}
+
+	min, max := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(t.Size))
+	if t.T == abi.IntTy {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+		return reflect.Value{}, fmt.Errorf("%s is out of range for %s", n, t.String())
+	}
+	if t.GetType() == reflect.TypeOf(&big.Int{}) {
+		return reflect.ValueOf(n), nil
+	}
+	return convertInteger(t.GetType(), n)
+}
+
+func lookupMethod(contractAbi abi.ABI, name string) *abi.Method {
+	if m, ok := contractAbi.Methods[name]; ok {
+		return &m
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package multicall
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const ledgerTestAbi = `[
+	{"type":"function","name":"entry","stateMutability":"view","inputs":[{"name":"account","type":"address"},{"name":"ids","type":"uint256[]"}],"outputs":[
+		{"name":"","type":"tuple","components":[
+			{"name":"owner","type":"address"},{"name":"amount","type":"uint256"},{"name":"delta","type":"int64"},
+			{"name":"tag","type":"bytes4"},{"name":"memo","type":"bytes"},{"name":"active","type":"bool"},
+			{"name":"parts","type":"tuple[]","components":[{"name":"label","type":"string"},{"name":"weight","type":"uint8"}]}]}]},
+	{"type":"function","name":"totals","stateMutability":"view","inputs":[],"outputs":[{"name":"supply","type":"uint256"},{"name":"holders","type":"uint32"}]}
+]`
+
+type ledgerPart struct {
+	Label  string
+	Weight uint8
+}
+
+type ledgerEntry struct {
+	Owner  common.Address
+	Amount *big.Int
+	Delta  int64
+	Tag    [4]byte
+	Memo   []byte
+	Active bool
+	Parts  []ledgerPart
+}
+
+type ledgerTotals struct {
+	Supply  *big.Int
+	Holders uint32
+}
+
+func TestABIJSON_Values(t *testing.T) {
+	ledgerAbi := mustParseABI(t, ledgerTestAbi)
+	entryType := ledgerAbi.Methods["entry"].Outputs[0].Type
+	amount, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	entry := ledgerEntry{
+		Owner:  common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11"),
+		Amount: amount,
+		Delta:  -5,
+		Tag:    [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Memo:   []byte{1, 2},
+		Active: true,
+		Parts:  []ledgerPart{{Label: "a", Weight: 7}},
+	}
+
+	encoded, err := MarshalValueJSON(entryType, entry)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"owner":"0xcA11bde05977b3631167028862bE2a173976CA11",
+		"amount":"123456789012345678901234567890",
+		"delta":"-5",
+		"tag":"0xdeadbeef",
+		"memo":"0x0102",
+		"active":true,
+		"parts":[{"label":"a","weight":"7"}]
+	}`, string(encoded))
+	// keys keep the ABI's order.
+	assert.Contains(t, string(encoded), `{"owner":"0xcA11bde05977b3631167028862bE2a173976CA11","amount"`)
+
+	decoded, err := UnmarshalValueJSON(entryType, encoded)
+	assert.NoError(t, err)
+	// decoded values are what go-ethereum would have unpacked, so they pack and convert like any other.
+	packed, err := ledgerAbi.Methods["entry"].Outputs.Pack(decoded)
+	assert.NoError(t, err)
+	roundTripped, err := unpackOutputs[ledgerEntry](ledgerAbi.Methods["entry"].Outputs, packed)
+	assert.NoError(t, err)
+	assert.Equal(t, entry, *roundTripped)
+
+	_, err = UnmarshalValueJSON(entryType, []byte(`{"owner":"0x01"}`))
+	assert.Error(t, err)
+	_, err = UnmarshalValueJSON(ledgerAbi.Methods["totals"].Outputs[1].Type, []byte(`"4294967296"`))
+	assert.ErrorContains(t, err, "out of range")
+	holders, err := UnmarshalValueJSON(ledgerAbi.Methods["totals"].Outputs[1].Type, []byte(`12`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(12), holders)
+
+	// integers are decimal unless 0x-prefixed; a leading zero doesn't make them octal.
+	for input, expected := range map[string]uint32{`"010"`: 10, `"0x10"`: 16, `"0X1f"`: 31} {
+		holders, err = UnmarshalValueJSON(ledgerAbi.Methods["totals"].Outputs[1].Type, []byte(input))
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, holders, input)
+	}
+	for _, input := range []string{`"0b1"`, `"0o7"`, `"1_000"`, `"--1"`, `"0x"`} {
+		_, err = UnmarshalValueJSON(ledgerAbi.Methods["totals"].Outputs[1].Type, []byte(input))
+		assert.Error(t, err, input)
+	}
+	delta, err := UnmarshalValueJSON(*ledgerAbi.Methods["entry"].Outputs[0].Type.TupleElems[2], []byte(`"-0x10"`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-16), delta)
+}
+
+func TestABIJSON_Calls(t *testing.T) {
+	ledgerAbi := mustParseABI(t, ledgerTestAbi)
+	call, err := Describe[ledgerEntry](fakeAddress(0x10), ledgerAbi, "entry", fakeAddress(0x0e), []*big.Int{big.NewInt(1), big.NewInt(2)})
+	assert.NoError(t, err)
+
+	encoded, err := json.Marshal(call)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"target":"`+fakeAddress(0x10).Hex()+`",
+		"method":"entry(address,uint256[])",
+		"signature":"function entry(address account, uint256[] ids) view returns ((address owner, uint256 amount, int64 delta, bytes4 tag, bytes memo, bool active, (string label, uint8 weight)[] parts))",
+		"args":{"account":"`+fakeAddress(0x0e).Hex()+`","ids":["1","2"]}
+	}`, string(encoded))
+
+	args, err := UnmarshalArgumentsJSON(ledgerAbi.Methods["entry"].Inputs, []byte(`{"account":"`+fakeAddress(0x0e).Hex()+`","ids":["1","0x2"]}`))
+	assert.NoError(t, err)
+	repacked, err := ledgerAbi.Pack("entry", args...)
+	assert.NoError(t, err)
+	assert.Equal(t, call.Data, repacked)
+
+	// without an ABI, the raw calldata is kept instead.
+	raw := &MultiCallMetaData[int]{Address: fakeAddress(0x10), Data: []byte{1, 2, 3, 4}, FunctionName: "unknown"}
+	encoded, err = json.Marshal(raw)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"target":"`+fakeAddress(0x10).Hex()+`","method":"unknown","data":"0x01020304"}`, string(encoded))
+}
+
+func TestABIJSON_CallRoundTrip(t *testing.T) {
+	ledgerAbi := mustParseABI(t, ledgerTestAbi)
+	call, err := Describe[ledgerEntry](fakeAddress(0x10), ledgerAbi, "entry", fakeAddress(0x0e), []*big.Int{big.NewInt(1), big.NewInt(2)})
+	assert.NoError(t, err)
+	encoded, err := json.Marshal(call)
+	assert.NoError(t, err)
+
+	var decoded MultiCallMetaData[ledgerEntry]
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, call.Address, decoded.Address)
+	assert.Equal(t, call.Data, decoded.Data)
+	assert.Equal(t, call.FunctionName, decoded.FunctionName)
+	assert.Equal(t, call.Method.Sig, decoded.Method.Sig)
+	assert.Equal(t, call.Method.StateMutability, decoded.Method.StateMutability)
+	assert.Equal(t, call.Method.Outputs[0].Type.String(), decoded.Method.Outputs[0].Type.String())
+	reencoded, err := json.Marshal(&decoded)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(encoded), string(reencoded))
+
+	// the decoded call deserializes results like the original.
+	entry := ledgerEntry{Owner: fakeAddress(0x0e), Amount: big.NewInt(5), Tag: [4]byte{1}, Memo: []byte{}, Parts: []ledgerPart{{Label: "a", Weight: 1}}}
+	output, err := ledgerAbi.Methods["entry"].Outputs.Pack(entry)
+	assert.NoError(t, err)
+	value, err := decoded.Deserialize(output)
+	assert.NoError(t, err)
+	assert.Equal(t, entry.Parts, value.Parts)
+	assert.Equal(t, "5", value.Amount.String())
+
+	totals, err := Describe[ledgerTotals](fakeAddress(0x10), ledgerAbi, "totals")
+	assert.NoError(t, err)
+	encoded, err = json.Marshal(totals)
+	assert.NoError(t, err)
+	var decodedTotals MultiCallMetaData[ledgerTotals]
+	assert.NoError(t, json.Unmarshal(encoded, &decodedTotals))
+	assert.Equal(t, totals.Data, decodedTotals.Data)
+
+	// raw calls can't be decoded into T, but fail when they're made rather than panicking.
+	chain := newFakeChain()
+	token := fakeAddress(0x20)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+	balance, err := DescribeSig[big.Int](token, "balanceOf(address)(uint256)", fakeAddress(1))
+	assert.NoError(t, err)
+	raw := &MultiCallMetaData[int]{Address: token, Data: balance.Data, FunctionName: "unknown"}
+	encoded, err = json.Marshal(raw)
+	assert.NoError(t, err)
+	var decodedRaw MultiCallMetaData[int]
+	assert.NoError(t, json.Unmarshal(encoded, &decodedRaw))
+	assert.Equal(t, raw.Address, decodedRaw.Address)
+	assert.Equal(t, raw.Data, decodedRaw.Data)
+	assert.Equal(t, raw.FunctionName, decodedRaw.FunctionName)
+	results, err := DoManyResults(mc, &decodedRaw)
+	assert.NoError(t, err)
+	assert.ErrorContains(t, results[0].Err(), "no ABI to decode unknown with")
+
+	// ...unless T is []byte, which gets the return data as-is.
+	var decodedBytes MultiCallMetaData[[]byte]
+	assert.NoError(t, json.Unmarshal(encoded, &decodedBytes))
+	returned, err := DoMany(mc, &decodedBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeBalance(fakeAddress(1)).String(), new(big.Int).SetBytes(*(*returned)[0]).String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"target":"`+fakeAddress(0x10).Hex()+`","method":"totals()","signature":"function entry(address a)","args":{}}`), &decodedTotals))
+}
+
+func TestABIJSON_Results(t *testing.T) {
+	ledgerAbi := mustParseABI(t, ledgerTestAbi)
+	totals, err := Describe[ledgerTotals](fakeAddress(0x10), ledgerAbi, "totals")
+	assert.NoError(t, err)
+
+	encoded, err := MarshalResultJSON(totals, okResult(ledgerTotals{Supply: big.NewInt(1e18), Holders: 3}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ok":true,"value":{"supply":"1000000000000000000","holders":"3"}}`, string(encoded))
+	decoded, err := UnmarshalResultJSON(totals, encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, ledgerTotals{Supply: big.NewInt(1e18), Holders: 3}, decoded.Value)
+
+	failed := failedResult[ledgerTotals](DecodeRevert(fakeRevertData(revertWithReason("paused"))))
+	encoded, err = MarshalResultJSON(totals, failed)
+	assert.NoError(t, err)
+	decoded, err = UnmarshalResultJSON(totals, encoded)
+	assert.NoError(t, err)
+	assert.False(t, decoded.Ok())
+	var revert *RevertError
+	assert.True(t, errors.As(decoded.Err(), &revert))
+	assert.Equal(t, "paused", revert.Reason)
+}
@@ -0,0 +1,163 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The block a chunk of calls executed in, as reported by the multicall contract in the same eth_call.
+type BlockAttestation struct {
+	Number uint64
+	Hash   common.Hash
+	// The chunk's results are `Results[FirstIndex : FirstIndex+Count]`.
+	FirstIndex int
+	Count      int
+}
+
+// Results along with the block(s) they were read at. There's one attestation per eth_call the batch was split into.
+type AttestedResults[A any] struct {
+	Results []Result[A]
+	Blocks  []BlockAttestation
+}
+
+// Returns the block every result was read at, or an error if the chunks of the batch landed on different blocks.
+func (r *AttestedResults[A]) Block() (uint64, common.Hash, error) {
+	if len(r.Blocks) == 0 {
+		return 0, common.Hash{}, errors.New("no calls were made")
+	}
+	first := r.Blocks[0]
+	for _, block := range r.Blocks[1:] {
+		if block.Number != first.Number || block.Hash != first.Hash {
+			return 0, common.Hash{}, fmt.Errorf("results span blocks %d to %d", first.Number, block.Number)
+		}
+	}
+	return first.Number, first.Hash, nil
+}
+
+// Returns the attestation of the chunk holding the i-th result.
+func (r *AttestedResults[A]) BlockOf(i int) (BlockAttestation, bool) {
+	for _, block := range r.Blocks {
+		if i >= block.FirstIndex && i < block.FirstIndex+block.Count {
+			return block, true
+		}
+	}
+	return BlockAttestation{}, false
+}
+
+/*
+ * Like `DoManyResults()`, but made with Multicall3's `tryBlockAndAggregate`, so every eth_call also reports the
+ * number of the block it executed in.
+ *
+ * Multicall3 reports `blockhash(block.number)`, which the EVM defines as zero, so the hash comes from the node
+ * instead: the block is looked up first and the calls are then made at its hash, so the results can't come from
+ * another block, even across a reorg. Pending calls have no hash to pin, and neither do clients that weren't
+ * created with `NewMulticallClient`; their attestations hold whatever the contract reported, i.e. a zero hash.
+ */
+func DoManyAttested[A any](mc *MulticallClient, requests ...*MultiCallMetaData[A]) (*AttestedResults[A], error) {
+	return DoManyAttestedWithOptions(mc, nil, requests...)
+}
+
+func DoManyAttestedWithOptions[A any](mc *MulticallClient, options *bind.CallOpts, requests ...*MultiCallMetaData[A]) (*AttestedResults[A], error) {
+	return doManyAttested(mc, options, false, requests...)
+}
+
+// Like `DoManyAttested()`, but made with `blockAndAggregate`: any failing call fails the whole batch.
+func DoManyAttestedStrict[A any](mc *MulticallClient, requests ...*MultiCallMetaData[A]) (*AttestedResults[A], error) {
+	return DoManyAttestedStrictWithOptions(mc, nil, requests...)
+}
+
+func DoManyAttestedStrictWithOptions[A any](mc *MulticallClient, options *bind.CallOpts, requests ...*MultiCallMetaData[A]) (*AttestedResults[A], error) {
+	return doManyAttested(mc, options, true, requests...)
+}
+
+func doManyAttested[A any](mc *MulticallClient, options *bind.CallOpts, requireSuccess bool, requests ...*MultiCallMetaData[A]) (*AttestedResults[A], error) {
+	calls := mapCollection(requests, func(md *MultiCallMetaData[A], index uint64) RawMulticall {
+		return md.rawCall()
+	})
+	pinned, err := mc.pinBlockHash(mc.callOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	var blocks []BlockAttestation
+	res, err := executeMulticallWith(mc, pinned, calls, mc.blockAndAggregate(requireSuccess, &blocks))
+	if err != nil {
+		return nil, fmt.Errorf("multicall failed: %s", err.Error())
+	}
+
+	results := mapCollection(requests, func(md *MultiCallMetaData[A], i uint64) Result[A] {
+		return decodeResult(md, res[i])
+	})
+	if requireSuccess {
+		for i, result := range results {
+			if !result.ok {
+				return nil, fmt.Errorf("call %d failed: %w", i, result.Err())
+			}
+		}
+	}
+	return &AttestedResults[A]{Results: results, Blocks: blocks}, nil
+}
+
+// blockAndAggregate returns an aggregator that calls (try)BlockAndAggregate, appending each chunk's block to `blocks`.
+func (mc *MulticallClient) blockAndAggregate(requireSuccess bool, blocks *[]BlockAttestation) aggregator {
+	return func(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+		if totalValue(calls).Sign() > 0 {
+			return nil, errors.New("calls sending a value can't be attested")
+		}
+		if mc.Dialect == DialectMulticall1 {
+			return nil, fmt.Errorf("%s doesn't report block hashes", mc.Dialect)
+		}
+		var res []interface{}
+		method := "tryBlockAndAggregate"
+		var err error
+		if requireSuccess {
+			method = "blockAndAggregate"
+			err = mc.Contract.Call(opts, &res, method, plainCalls(calls))
+		} else {
+			err = mc.Contract.Call(opts, &res, method, false, plainCalls(calls))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %s", method, err)
+		}
+
+		number := res[0].(*big.Int)
+		hash := common.Hash(res[1].([32]byte))
+		if hash == (common.Hash{}) {
+			hash = opts.BlockHash
+		}
+
+		firstIndex := 0
+		if n := len(*blocks); n > 0 {
+			firstIndex = (*blocks)[n-1].FirstIndex + (*blocks)[n-1].Count
+		}
+		*blocks = append(*blocks, BlockAttestation{
+			Number:     number.Uint64(),
+			Hash:       hash,
+			FirstIndex: firstIndex,
+			Count:      len(calls),
+		})
+		return *abi.ConvertType(res[2], new([]Multicall3Result)).(*[]Multicall3Result), nil
+	}
+}
+
+// pinBlockHash resolves the block `opts` reads at to its hash, so that calls made with the result all read that block.
+func (mc *MulticallClient) pinBlockHash(opts *bind.CallOpts) (*bind.CallOpts, error) {
+	pinned := bind.CallOpts{}
+	if opts != nil {
+		pinned = *opts
+	}
+	if pinned.BlockHash != (common.Hash{}) || pinned.Pending || mc.Client == nil {
+		return &pinned, nil
+	}
+	header, err := mc.Client.HeaderByNumber(callContext(mc, opts), pinned.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin block: %s", err.Error())
+	}
+	pinned.BlockNumber = nil
+	pinned.BlockHash = header.Hash()
+	return &pinned, nil
+}
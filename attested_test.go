@@ -0,0 +1,101 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoManyAttested(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0xa0)
+	chain.contracts[token] = fakeERC20("A", 18)
+	mc := newFakeClient(t, chain)
+	erc20 := mustParseABI(t, erc20TestAbi)
+
+	blocked := common.HexToAddress("0xdead000000000000000000000000000000000001")
+	holders := []common.Address{fakeAddress(1), blocked, fakeAddress(3)}
+	calls := make([]*MultiCallMetaData[big.Int], len(holders))
+	for i, holder := range holders {
+		call, err := Describe[big.Int](token, erc20, "balanceOf", holder)
+		assert.NoError(t, err)
+		calls[i] = call
+	}
+
+	// the contract reports a zero hash, so it comes from the header of the block the calls are pinned to.
+	attested, err := DoManyAttested(mc, calls...)
+	assert.NoError(t, err)
+	assert.Len(t, attested.Results, 3)
+	assert.Equal(t, *fakeBalance(holders[0]), attested.Results[0].Value)
+	assert.Error(t, attested.Results[1].Err())
+	assert.Equal(t, []BlockAttestation{{Number: 100, Hash: fakeHeader(100).Hash(), Count: 3}}, attested.Blocks)
+	number, hash, err := attested.Block()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), number)
+	assert.Equal(t, fakeHeader(100).Hash(), hash)
+	assert.Equal(t, 1, chain.headerCalls)
+
+	old, err := DoManyAttestedWithOptions(mc, &bind.CallOpts{BlockNumber: big.NewInt(42)}, calls...)
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockAttestation{{Number: 42, Hash: fakeHeader(42).Hash(), Count: 3}}, old.Blocks)
+
+	// a pending block has no hash yet.
+	pending, err := DoManyAttestedWithOptions(mc, &bind.CallOpts{Pending: true}, calls...)
+	assert.NoError(t, err)
+	assert.Equal(t, []BlockAttestation{{Number: 100, Count: 3}}, pending.Blocks)
+
+	_, err = DoManyAttestedStrict(mc, calls...)
+	assert.Error(t, err)
+	strict, err := DoManyAttestedStrict(mc, calls[0], calls[2])
+	assert.NoError(t, err)
+	assert.Equal(t, *fakeBalance(holders[2]), strict.Results[1].Value)
+}
+
+func TestDoManyAttestedChunks(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0xa0)
+	chain.contracts[token] = fakeERC20("A", 18)
+	ticker := fakeAddress(0xb0)
+	// mines a block every time it's called, but every chunk still reads the block the batch was pinned to.
+	chain.contracts[ticker] = func(ctx fakeCallContext, data []byte) ([]byte, error) {
+		ctx.chain.blockNumber++
+		return nil, nil
+	}
+	mc := newFakeClient(t, chain)
+	mc.MaxBatchSize = 36 * 2
+	erc20 := mustParseABI(t, erc20TestAbi)
+
+	calls := make([]*MultiCallMetaData[big.Int], 5)
+	for i := range calls {
+		call, err := Describe[big.Int](token, erc20, "balanceOf", fakeAddress(i+1))
+		assert.NoError(t, err)
+		calls[i] = call
+	}
+	tick, err := Describe[big.Int](ticker, erc20, "totalSupply")
+	assert.NoError(t, err)
+	calls[1] = tick
+
+	attested, err := DoManyAttested(mc, calls...)
+	assert.NoError(t, err)
+	assert.Len(t, attested.Blocks, 3)
+	assert.Equal(t, 2, attested.Blocks[1].FirstIndex)
+	number, hash, err := attested.Block()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), number)
+	assert.Equal(t, fakeHeader(100).Hash(), hash)
+	assert.Greater(t, chain.blockNumber, uint64(100))
+
+	block, ok := attested.BlockOf(4)
+	assert.True(t, ok)
+	assert.Equal(t, 4, block.FirstIndex)
+	_, ok = attested.BlockOf(5)
+	assert.False(t, ok)
+
+	// chunks can only land on different blocks when they aren't pinned, e.g. for pending calls.
+	attested.Blocks[2].Number = 101
+	_, _, err = attested.Block()
+	assert.Error(t, err)
+}
@@ -0,0 +1,507 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// An in-memory chain that speaks just enough JSON-RPC for the multicall client to run against it, so
+// tests don't need a live node.
+
+type fakeCallContext struct {
+	chain *fakeChain
+	self  common.Address
+	from  common.Address
+	value *big.Int
+	block uint64
+}
+
+type fakeContract func(ctx fakeCallContext, data []byte) ([]byte, error)
+
+// fakeRevert is returned by fake contracts to signal a revert with the given data.
+type fakeRevert struct {
+	data []byte
+}
+
+func (r *fakeRevert) Error() string          { return "execution reverted" }
+func (r *fakeRevert) ErrorCode() int         { return 3 }
+func (r *fakeRevert) ErrorData() interface{} { return hexutil.Encode(r.data) }
+
+type fakeChain struct {
+	lock        sync.Mutex
+	blockNumber uint64
+	contracts   map[common.Address]fakeContract
+	ethCalls    int
+	headerCalls int
+	// transactions sent with eth_sendRawTransaction, each mined in its own block.
+	sent     []*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	// the most requests the node accepts in a JSON-RPC batch, if it's limited.
+	rpcBatchLimit int
+}
+
+func newFakeChain() *fakeChain {
+	chain := &fakeChain{
+		blockNumber: 100,
+		contracts:   map[common.Address]fakeContract{},
+		receipts:    map[common.Hash]*types.Receipt{},
+	}
+	chain.contracts[common.HexToAddress(defaultMulticallAddress)] = fakeMulticall3()
+	return chain
+}
+
+func (c *fakeChain) call(ctx fakeCallContext, to common.Address, value *big.Int, data []byte) ([]byte, error) {
+	contract, ok := c.contracts[to]
+	if !ok {
+		// calls to accounts without code succeed with no data.
+		return nil, nil
+	}
+	// the caller's address becomes msg.sender of the nested call.
+	return contract(fakeCallContext{chain: c, self: to, from: ctx.self, value: value, block: ctx.block}, data)
+}
+
+type fakeCallArgs struct {
+	From  *common.Address `json:"from"`
+	To    *common.Address `json:"to"`
+	Data  *hexutil.Bytes  `json:"data"`
+	Input *hexutil.Bytes  `json:"input"`
+	Value *hexutil.Big    `json:"value"`
+}
+
+type fakeOverrideAccount struct {
+	Code *hexutil.Bytes `json:"code"`
+}
+
+type fakeEthService struct {
+	chain *fakeChain
+}
+
+// fakeCode is the code GetCode reports for a fake contract. Overriding an account's code with it installs that
+// contract at the account for the duration of the call.
+func fakeCode(address common.Address) []byte {
+	return append([]byte{0xfe}, address.Bytes()...)
+}
+
+func (s *fakeEthService) Call(args fakeCallArgs, block *rpc.BlockNumberOrHash, overrides *map[common.Address]fakeOverrideAccount) (hexutil.Bytes, error) {
+	s.chain.lock.Lock()
+	defer s.chain.lock.Unlock()
+	s.chain.ethCalls++
+
+	chain := s.chain
+	if overrides != nil {
+		chain = &fakeChain{blockNumber: s.chain.blockNumber, contracts: map[common.Address]fakeContract{}}
+		for address, contract := range s.chain.contracts {
+			chain.contracts[address] = contract
+		}
+		for address, account := range *overrides {
+			if account.Code == nil {
+				continue
+			}
+			installed := false
+			for source, contract := range s.chain.contracts {
+				if string(fakeCode(source)) == string(*account.Code) {
+					chain.contracts[address] = contract
+					installed = true
+				}
+			}
+			if !installed {
+				return nil, fmt.Errorf("unknown code overridden onto %s", address)
+			}
+		}
+	}
+
+	if args.To == nil {
+		return nil, errors.New("contract creation not supported")
+	}
+	data := []byte{}
+	if args.Input != nil {
+		data = *args.Input
+	} else if args.Data != nil {
+		data = *args.Data
+	}
+	ctx := fakeCallContext{chain: chain, block: s.chain.blockNumber}
+	if args.From != nil {
+		ctx.self = *args.From
+	}
+	if block != nil {
+		if number, ok := block.Number(); ok && number >= 0 {
+			ctx.block = uint64(number)
+		}
+		if hash, ok := block.Hash(); ok {
+			number, found := s.chain.blockByHash(hash)
+			if !found {
+				return nil, fmt.Errorf("unknown block %s", hash)
+			}
+			ctx.block = number
+		}
+	}
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+	return chain.call(ctx, *args.To, value, data)
+}
+
+func (s *fakeEthService) GetCode(address common.Address, block *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	s.chain.lock.Lock()
+	defer s.chain.lock.Unlock()
+	if _, ok := s.chain.contracts[address]; ok {
+		return fakeCode(address), nil
+	}
+	return hexutil.Bytes{}, nil
+}
+
+func (s *fakeEthService) EstimateGas(args fakeCallArgs, block *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if _, err := s.Call(args, block, nil); err != nil {
+		return 0, err
+	}
+	return 100_000, nil
+}
+
+// SendRawTransaction executes the transaction right away, mining it in a new block.
+func (s *fakeEthService) SendRawTransaction(encoded hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encoded); err != nil {
+		return common.Hash{}, err
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	s.chain.lock.Lock()
+	defer s.chain.lock.Unlock()
+	s.chain.blockNumber++
+	ctx := fakeCallContext{chain: s.chain, self: sender, block: s.chain.blockNumber}
+	status := types.ReceiptStatusSuccessful
+	if _, err := s.chain.call(ctx, *tx.To(), tx.Value(), tx.Data()); err != nil {
+		status = types.ReceiptStatusFailed
+	}
+	s.chain.sent = append(s.chain.sent, tx)
+	s.chain.receipts[tx.Hash()] = &types.Receipt{
+		Type:              tx.Type(),
+		Status:            status,
+		CumulativeGasUsed: 50_000,
+		GasUsed:           50_000,
+		Logs:              []*types.Log{},
+		TxHash:            tx.Hash(),
+		BlockNumber:       new(big.Int).SetUint64(s.chain.blockNumber),
+	}
+	return tx.Hash(), nil
+}
+
+func (s *fakeEthService) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
+	s.chain.lock.Lock()
+	defer s.chain.lock.Unlock()
+	return s.chain.receipts[hash], nil
+}
+
+func (s *fakeEthService) GetBlockByNumber(number rpc.BlockNumber, full bool) (*types.Header, error) {
+	s.chain.lock.Lock()
+	defer s.chain.lock.Unlock()
+	s.chain.headerCalls++
+	switch {
+	case number >= 0:
+		return fakeHeader(uint64(number)), nil
+	case number == rpc.PendingBlockNumber:
+		return nil, errors.New("pending block has no header")
+	}
+	return fakeHeader(s.chain.blockNumber), nil
+}
+
+// fakeHeader is the header of block `number`, whose hash is the block's hash throughout the fake chain.
+func fakeHeader(number uint64) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: new(big.Int),
+		Time:       number * 12,
+		GasLimit:   30_000_000,
+	}
+}
+
+func (c *fakeChain) blockByHash(hash common.Hash) (uint64, bool) {
+	for number := c.blockNumber; ; number-- {
+		if fakeHeader(number).Hash() == hash {
+			return number, true
+		}
+		if number == 0 {
+			return 0, false
+		}
+	}
+}
+
+func (s *fakeEthService) BlockNumber() hexutil.Uint64 {
+	s.chain.lock.Lock()
+	defer s.chain.lock.Unlock()
+	return hexutil.Uint64(s.chain.blockNumber)
+}
+
+func newFakeClient(t *testing.T, chain *fakeChain) *MulticallClient {
+	t.Helper()
+	server := rpc.NewServer()
+	server.SetBatchLimits(chain.rpcBatchLimit, 0)
+	if err := server.RegisterName("eth", &fakeEthService{chain: chain}); err != nil {
+		t.Fatalf("failed to register fake eth service: %v", err)
+	}
+	eth := ethclient.NewClient(rpc.DialInProc(server))
+	t.Cleanup(eth.Close)
+
+	mc, err := NewMulticallClient(context.Background(), eth, nil)
+	if err != nil {
+		t.Fatalf("failed to create multicall client: %v", err)
+	}
+	return mc
+}
+
+type fakeMethod func(ctx fakeCallContext, args []interface{}) ([]interface{}, error)
+
+// abiContract dispatches calldata to handlers keyed by (unique) method name.
+func abiContract(parsed abi.ABI, handlers map[string]fakeMethod) fakeContract {
+	return func(ctx fakeCallContext, data []byte) ([]byte, error) {
+		if len(data) < 4 {
+			return nil, &fakeRevert{}
+		}
+		method, err := parsed.MethodById(data[:4])
+		if err != nil {
+			return nil, &fakeRevert{}
+		}
+		handler, ok := handlers[method.Name]
+		if !ok {
+			return nil, &fakeRevert{}
+		}
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, &fakeRevert{}
+		}
+		out, err := handler(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return method.Outputs.Pack(out...)
+	}
+}
+
+// revertWithReason builds an Error(string) revert, as emitted by `require(cond, reason)`.
+func revertWithReason(reason string) error {
+	stringType, _ := abi.NewType("string", "", nil)
+	encoded, _ := abi.Arguments{{Type: stringType}}.Pack(reason)
+	return &fakeRevert{data: append(common.FromHex("0x08c379a0"), encoded...)}
+}
+
+type fakeCall struct {
+	Target   common.Address
+	CallData []byte
+}
+
+type fakeCall3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type fakeCall3Value struct {
+	Target       common.Address
+	AllowFailure bool
+	Value        *big.Int
+	CallData     []byte
+}
+
+func fakeRevertData(err error) []byte {
+	var revert *fakeRevert
+	if errors.As(err, &revert) {
+		return revert.data
+	}
+	return nil
+}
+
+// fakeMulticall3 emulates the Multicall3 contract on top of the fake chain.
+func fakeMulticall3() fakeContract {
+	parsed := panicIfError(abi.JSON(strings.NewReader(multicallAbi)))
+	tryCalls := func(ctx fakeCallContext, requireSuccess bool, calls []fakeCall) ([]Multicall3Result, error) {
+		results := make([]Multicall3Result, len(calls))
+		for i, call := range calls {
+			out, err := ctx.chain.call(ctx, call.Target, new(big.Int), call.CallData)
+			if err != nil && requireSuccess {
+				return nil, &fakeRevert{}
+			}
+			results[i] = Multicall3Result{Success: err == nil, ReturnData: out}
+			if err != nil {
+				results[i].ReturnData = fakeRevertData(err)
+			}
+		}
+		return results, nil
+	}
+	return abiContract(parsed, map[string]fakeMethod{
+		"aggregate3": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			calls := *abi.ConvertType(args[0], new([]fakeCall3)).(*[]fakeCall3)
+			results := make([]Multicall3Result, len(calls))
+			for i, call := range calls {
+				out, err := ctx.chain.call(ctx, call.Target, new(big.Int), call.CallData)
+				if err != nil && !call.AllowFailure {
+					return nil, &fakeRevert{}
+				}
+				results[i] = Multicall3Result{Success: err == nil, ReturnData: out}
+				if err != nil {
+					results[i].ReturnData = fakeRevertData(err)
+				}
+			}
+			return []interface{}{results}, nil
+		},
+		"aggregate3Value": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			calls := *abi.ConvertType(args[0], new([]fakeCall3Value)).(*[]fakeCall3Value)
+			total := new(big.Int)
+			results := make([]Multicall3Result, len(calls))
+			for i, call := range calls {
+				total.Add(total, call.Value)
+				out, err := ctx.chain.call(ctx, call.Target, call.Value, call.CallData)
+				if err != nil && !call.AllowFailure {
+					return nil, &fakeRevert{}
+				}
+				results[i] = Multicall3Result{Success: err == nil, ReturnData: out}
+				if err != nil {
+					results[i].ReturnData = fakeRevertData(err)
+				}
+			}
+			if ctx.value == nil || total.Cmp(ctx.value) != 0 {
+				return nil, revertWithReason("Multicall3: value mismatch")
+			}
+			return []interface{}{results}, nil
+		},
+		"aggregate": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			calls := *abi.ConvertType(args[0], new([]fakeCall)).(*[]fakeCall)
+			results, err := tryCalls(ctx, true, calls)
+			if err != nil {
+				return nil, err
+			}
+			returnData := mapCollection(results, func(r Multicall3Result, _ uint64) []byte { return r.ReturnData })
+			return []interface{}{new(big.Int).SetUint64(ctx.block), returnData}, nil
+		},
+		"tryAggregate": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			calls := *abi.ConvertType(args[1], new([]fakeCall)).(*[]fakeCall)
+			results, err := tryCalls(ctx, args[0].(bool), calls)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{results}, nil
+		},
+		"blockAndAggregate": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			calls := *abi.ConvertType(args[0], new([]fakeCall)).(*[]fakeCall)
+			results, err := tryCalls(ctx, true, calls)
+			if err != nil {
+				return nil, err
+			}
+			// like the EVM, Multicall3 sees blockhash(block.number) as zero.
+			return []interface{}{new(big.Int).SetUint64(ctx.block), common.Hash{}, results}, nil
+		},
+		"tryBlockAndAggregate": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			calls := *abi.ConvertType(args[1], new([]fakeCall)).(*[]fakeCall)
+			results, err := tryCalls(ctx, args[0].(bool), calls)
+			if err != nil {
+				return nil, err
+			}
+			// like the EVM, Multicall3 sees blockhash(block.number) as zero.
+			return []interface{}{new(big.Int).SetUint64(ctx.block), common.Hash{}, results}, nil
+		},
+		"getBlockNumber": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{new(big.Int).SetUint64(ctx.block)}, nil
+		},
+		"getBlockHash": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{fakeHeader(args[0].(*big.Int).Uint64()).Hash()}, nil
+		},
+		"getLastBlockHash": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{fakeHeader(ctx.block - 1).Hash()}, nil
+		},
+		"getEthBalance": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{new(big.Int).SetBytes(args[0].(common.Address).Bytes()[:2])}, nil
+		},
+		"getBasefee": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(7_000_000_000)}, nil
+		},
+		"getChainId": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(1)}, nil
+		},
+		"getCurrentBlockTimestamp": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{new(big.Int).SetUint64(1_700_000_000 + 12*ctx.block)}, nil
+		},
+		"getCurrentBlockCoinbase": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{common.HexToAddress("0xc0ffee")}, nil
+		},
+		"getCurrentBlockGasLimit": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(30_000_000)}, nil
+		},
+		"getCurrentBlockDifficulty": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(0)}, nil
+		},
+	})
+}
+
+const erc20TestAbi = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+// fakeERC20 returns a token whose balances are derived from the holder address, so tests can predict them
+// without any setup. Holders whose address starts with 0xdead make balanceOf revert.
+func fakeERC20(symbol string, decimals uint8) fakeContract {
+	parsed := panicIfError(abi.JSON(strings.NewReader(erc20TestAbi)))
+	return abiContract(parsed, map[string]fakeMethod{
+		"balanceOf": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			holder := args[0].(common.Address)
+			if holder[0] == 0xde && holder[1] == 0xad {
+				return nil, revertWithReason("blocked holder")
+			}
+			return []interface{}{fakeBalance(holder)}, nil
+		},
+		"decimals": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{decimals}, nil
+		},
+		"symbol": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{symbol}, nil
+		},
+		"totalSupply": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(1_000_000)}, nil
+		},
+		"transfer": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			if args[1].(*big.Int).Cmp(fakeBalance(ctx.from)) > 0 {
+				return nil, revertWithReason("ERC20: transfer amount exceeds balance")
+			}
+			return []interface{}{true}, nil
+		},
+	})
+}
+
+func fakeBalance(holder common.Address) *big.Int {
+	return new(big.Int).SetBytes(holder.Bytes()[18:])
+}
+
+func mustParseABIString(raw string) abi.ABI {
+	return panicIfError(abi.JSON(strings.NewReader(raw)))
+}
+
+func mustParseABI(t *testing.T, raw string) abi.ABI {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("failed to parse abi: %v", err)
+	}
+	return parsed
+}
+
+func fakeAddress(i int) common.Address {
+	return common.HexToAddress(fmt.Sprintf("0x%040x", i))
+}
@@ -0,0 +1,71 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The block a multicall executed in, as seen from inside the EVM.
+type BlockContext struct {
+	Number     uint64
+	ParentHash common.Hash
+	Timestamp  time.Time
+	Coinbase   common.Address
+	GasLimit   uint64
+	// Nil on Multicall2, which has no getter for it.
+	Basefee *big.Int
+	// `block.prevrandao` since the merge.
+	Difficulty *big.Int
+	// Nil on Multicall2, which has no getter for it.
+	ChainId *big.Int
+}
+
+// Reads every block-context getter of the multicall contract in a single call, so all values describe the same
+// block. Multicall1 has no `getBlockNumber()`, so it isn't supported.
+func (mc *MulticallClient) BlockContext() (*BlockContext, error) {
+	return mc.BlockContextWithOptions(nil)
+}
+
+func (mc *MulticallClient) BlockContextWithOptions(options *bind.CallOpts) (*BlockContext, error) {
+	if mc.Dialect == DialectMulticall1 {
+		return nil, fmt.Errorf("BlockContext is unsupported by %s", mc.Dialect)
+	}
+	calls := []RawMulticall{
+		mc.GetBlockNumber().Raw(),
+		mc.GetLastBlockHash().Raw(),
+		mc.GetCurrentBlockTimestamp().Raw(),
+		mc.GetCurrentBlockCoinbase().Raw(),
+		mc.GetCurrentBlockGasLimit().Raw(),
+		mc.GetCurrentBlockDifficulty().Raw(),
+	}
+	if mc.Dialect == DialectMulticall3 {
+		calls = append(calls, mc.GetBasefee().Raw(), mc.GetChainId().Raw())
+	}
+	res, err := doMultiCallMany(mc, options, calls...)
+	if err != nil {
+		return nil, fmt.Errorf("multicall failed: %s", err.Error())
+	}
+	for i, r := range res {
+		if !r.Success {
+			return nil, fmt.Errorf("%s failed: %v", calls[i].FunctionName, r.Value)
+		}
+	}
+
+	block := &BlockContext{
+		Number:     res[0].Value.(*big.Int).Uint64(),
+		ParentHash: *res[1].Value.(*common.Hash),
+		Timestamp:  *res[2].Value.(*time.Time),
+		Coinbase:   *res[3].Value.(*common.Address),
+		GasLimit:   *res[4].Value.(*uint64),
+		Difficulty: res[5].Value.(*big.Int),
+	}
+	if len(res) > 6 {
+		block.Basefee = res[6].Value.(*big.Int)
+		block.ChainId = res[7].Value.(*big.Int)
+	}
+	return block, nil
+}
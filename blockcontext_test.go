@@ -0,0 +1,65 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockContextGetters(t *testing.T) {
+	chain := newFakeChain()
+	mc := newFakeClient(t, chain)
+
+	hash, err := DoManyValues(mc, mc.GetBlockHash(90), mc.GetLastBlockHash())
+	assert.NoError(t, err)
+	assert.Equal(t, fakeHeader(90).Hash(), hash[0])
+	assert.Equal(t, fakeHeader(99).Hash(), hash[1])
+
+	timestamp, err := DoManyValues(mc, mc.GetCurrentBlockTimestamp())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1_700_000_000+12*100, 0).UTC(), timestamp[0])
+
+	coinbase, err := DoManyValues(mc, mc.GetCurrentBlockCoinbase())
+	assert.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0xc0ffee"), coinbase[0])
+
+	gasLimit, err := DoManyValues(mc, mc.GetCurrentBlockGasLimit())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(30_000_000), gasLimit[0])
+
+	values, err := DoManyValues(mc, mc.GetBasefee(), mc.GetChainId(), mc.GetCurrentBlockDifficulty())
+	assert.NoError(t, err)
+	assert.Equal(t, "7000000000", values[0].String())
+	assert.Equal(t, "1", values[1].String())
+	assert.Equal(t, "0", values[2].String())
+}
+
+func TestBlockContext(t *testing.T) {
+	chain := newFakeChain()
+	mc := newFakeClient(t, chain)
+
+	chain.ethCalls = 0
+	block, err := mc.BlockContext()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, chain.ethCalls)
+	assert.Zero(t, block.Difficulty.Sign())
+	block.Difficulty = nil
+	assert.Equal(t, &BlockContext{
+		Number:     100,
+		ParentHash: fakeHeader(99).Hash(),
+		Timestamp:  time.Unix(1_700_000_000+12*100, 0).UTC(),
+		Coinbase:   common.HexToAddress("0xc0ffee"),
+		GasLimit:   30_000_000,
+		Basefee:    big.NewInt(7_000_000_000),
+		ChainId:    big.NewInt(1),
+	}, block)
+
+	old, err := mc.BlockContextWithOptions(&bind.CallOpts{BlockNumber: big.NewInt(50)})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), old.Number)
+	assert.Equal(t, time.Unix(1_700_000_000+12*50, 0).UTC(), old.Timestamp)
+}
@@ -0,0 +1,181 @@
+package multicall
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The results of two zipped calls.
+type Pair[A any, B any] struct {
+	First  A
+	Second B
+}
+
+// Returns a call that performs `call` and then transforms its result, e.g. scaling a balance by its decimals.
+func Map[T any, U any](call *MultiCallMetaData[T], transform func(*T) (U, error)) *MultiCallMetaData[U] {
+	mapped := &MultiCallMetaData[U]{
+		Address:      call.Address,
+		Data:         call.Data,
+		FunctionName: call.FunctionName,
+		Method:       call.Method,
+		Value:        call.Value,
+		From:         call.From,
+		Deserialize: func(b []byte) (*U, error) {
+			val, err := call.Deserialize(b)
+			if err != nil {
+				return nil, err
+			}
+			if val == nil {
+				return nil, fmt.Errorf("%s returned a value that couldn't be deserialized", call.FunctionName)
+			}
+			out, err := transform(val)
+			if err != nil {
+				return nil, err
+			}
+			return &out, nil
+		},
+	}
+	if call.DecodeResult != nil {
+		mapped.DecodeResult = func(res Multicall3Result) (*U, error) {
+			val, err := decodeResult(call, res).Unwrap()
+			if err != nil {
+				return nil, err
+			}
+			out, err := transform(&val)
+			if err != nil {
+				return nil, err
+			}
+			return &out, nil
+		}
+	}
+	return mapped
+}
+
+/*
+ * Returns a single call that performs both `a` and `b`, succeeding only if both do.
+ *
+ * The pair is executed as a nested aggregate3 on the multicall contract, so it batches like any other call and
+ * both halves still see the multicall contract as msg.sender.
+ */
+func Zip[A any, B any](mc *MulticallClient, a *MultiCallMetaData[A], b *MultiCallMetaData[B]) (*MultiCallMetaData[Pair[A, B]], error) {
+	return describeNested(mc, "zip", []RawMulticall{a.rawCall(), b.rawCall()}, func(results []Multicall3Result) (*Pair[A, B], error) {
+		first, err := decodeResult(a, results[0]).Unwrap()
+		if err != nil {
+			return nil, fmt.Errorf("first call: %w", err)
+		}
+		second, err := decodeResult(b, results[1]).Unwrap()
+		if err != nil {
+			return nil, fmt.Errorf("second call: %w", err)
+		}
+		return &Pair[A, B]{First: first, Second: second}, nil
+	})
+}
+
+/*
+ * Returns a call that yields the result of `primary`, or that of `secondary` if the primary call fails or can't
+ * be deserialized.
+ *
+ * When both describe the same call (e.g. `symbol()` as a `string`, then as a `bytes32`), it's executed once and
+ * only the deserializers differ. Otherwise both calls are made in a nested aggregate3, like `Zip()`.
+ */
+func Fallback[T any](mc *MulticallClient, primary *MultiCallMetaData[T], secondary *MultiCallMetaData[T]) (*MultiCallMetaData[T], error) {
+	sameCall := primary.Address == secondary.Address && bytes.Equal(primary.Data, secondary.Data) &&
+		sameValue(primary.Value, secondary.Value) && sameFrom(primary.From, secondary.From)
+	if sameCall && primary.DecodeResult == nil && secondary.DecodeResult == nil {
+		return &MultiCallMetaData[T]{
+			Address:      primary.Address,
+			Data:         primary.Data,
+			FunctionName: primary.FunctionName,
+			Method:       primary.Method,
+			Value:        primary.Value,
+			From:         primary.From,
+			Deserialize: func(b []byte) (*T, error) {
+				if val, err := primary.Deserialize(b); err == nil && val != nil {
+					return val, nil
+				}
+				return secondary.Deserialize(b)
+			},
+		}, nil
+	}
+
+	return describeNested(mc, "fallback", []RawMulticall{primary.rawCall(), secondary.rawCall()}, func(results []Multicall3Result) (*T, error) {
+		if res := decodeResult(primary, results[0]); res.Ok() {
+			return &res.Value, nil
+		}
+		val, err := decodeResult(secondary, results[1]).Unwrap()
+		if err != nil {
+			return nil, err
+		}
+		return &val, nil
+	})
+}
+
+/*
+ * describeNested describes an aggregate3 call to the multicall contract itself, which runs `calls` as a unit. If
+ * any of them sends a value, it's an aggregate3Value call sending their total instead.
+ */
+func describeNested[T any](mc *MulticallClient, name string, calls []RawMulticall, deserialize func([]Multicall3Result) (*T, error)) (*MultiCallMetaData[T], error) {
+	if mc.Dialect != DialectMulticall3 {
+		return nil, fmt.Errorf("%s can't nest calls", mc.Dialect)
+	}
+	for _, call := range calls {
+		if call.From != nil {
+			return nil, fmt.Errorf("%s: calls with a From can't be nested", name)
+		}
+	}
+	multicallAbi := *mc.ABI
+	params := mapCollection(calls, func(call RawMulticall, _ uint64) ParamMulticall3Call3 {
+		value := call.Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		return ParamMulticall3Call3{Target: call.Address, AllowFailure: true, Value: value, CallData: call.Data}
+	})
+	method := "aggregate3"
+	value := totalValue(params)
+	if value.Sign() > 0 {
+		method = "aggregate3Value"
+	}
+	nested, err := DescribeWithDeserialize(
+		mc.Address,
+		multicallAbi,
+		func(b []byte) (*T, error) {
+			res, err := multicallAbi.Unpack(method, b)
+			if err != nil {
+				return nil, err
+			}
+			results := *abi.ConvertType(res[0], new([]Multicall3Result)).(*[]Multicall3Result)
+			if len(results) != len(calls) {
+				return nil, fmt.Errorf("%s: expected %d results, got %d", name, len(calls), len(results))
+			}
+			return deserialize(results)
+		},
+		method,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if value.Sign() > 0 {
+		nested.Value = value
+	}
+	return nested, nil
+}
+
+func sameValue(a *big.Int, b *big.Int) bool {
+	if a == nil || b == nil {
+		return (a == nil || a.Sign() == 0) && (b == nil || b.Sign() == 0)
+	}
+	return a.Cmp(b) == 0
+}
+
+func sameFrom(a *common.Address, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,99 @@
+package multicall
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeBytes32SymbolToken(symbol string) fakeContract {
+	parsed := mustParseABIString(`[{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]}]`)
+	return abiContract(parsed, map[string]fakeMethod{
+		"symbol": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			var out [32]byte
+			copy(out[:], symbol)
+			return []interface{}{out}, nil
+		},
+	})
+}
+
+func describeSymbol(t *testing.T, mc *MulticallClient, token [20]byte) *MultiCallMetaData[string] {
+	asString := panicIfError(DescribeSig[string](token, "symbol()(string)"))
+	asBytes32 := Map(panicIfError(DescribeSig[[32]byte](token, "symbol()(bytes32)")), func(b *[32]byte) (string, error) {
+		return string(bytes.TrimRight(b[:], "\x00")), nil
+	})
+	call, err := Fallback(mc, asString, asBytes32)
+	assert.NoError(t, err)
+	return call
+}
+
+func TestMapAndFallback(t *testing.T) {
+	chain := newFakeChain()
+	modern := fakeAddress(0x20)
+	legacy := fakeAddress(0x21)
+	chain.contracts[modern] = fakeERC20("TKN", 6)
+	chain.contracts[legacy] = fakeBytes32SymbolToken("MKR")
+	mc := newFakeClient(t, chain)
+
+	symbols, err := DoManyValues(mc, describeSymbol(t, mc, modern), describeSymbol(t, mc, legacy))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TKN", "MKR"}, symbols)
+	assert.Equal(t, 1, chain.ethCalls)
+
+	// transformation errors fail the call.
+	failing := Map(panicIfError(DescribeSig[big.Int](modern, "totalSupply()(uint256)")), func(v *big.Int) (int, error) {
+		return 0, errors.New("nope")
+	})
+	results, err := DoManyResults(mc, failing)
+	assert.NoError(t, err)
+	assert.EqualError(t, results[0].Err(), "nope")
+}
+
+func TestZip(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x20)
+	chain.contracts[token] = fakeERC20("TKN", 6)
+	mc := newFakeClient(t, chain)
+
+	holder := fakeAddress(0x1234)
+	balance, err := Zip(mc,
+		panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", holder)),
+		panicIfError(DescribeSig[uint8](token, "decimals()(uint8)")),
+	)
+	assert.NoError(t, err)
+
+	scaled := Map(balance, func(p *Pair[big.Int, uint8]) (float64, error) {
+		value, _ := new(big.Float).Quo(new(big.Float).SetInt(&p.First), big.NewFloat(1e6)).Float64()
+		return value, nil
+	})
+
+	// zipped calls batch with everything else.
+	supply, amount, err := Do(mc, panicIfError(DescribeSig[big.Int](token, "totalSupply()(uint256)")), scaled)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1_000_000), supply)
+	assert.InDelta(t, float64(0x1234)/1e6, *amount, 1e-12)
+	assert.Equal(t, 1, chain.ethCalls)
+
+	// if either half fails, so does the pair.
+	broken, err := Zip(mc,
+		panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", holder)),
+		panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", [20]byte{0xde, 0xad})),
+	)
+	assert.NoError(t, err)
+	results, err := DoManyResults(mc, broken)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err(), ErrCallFailed)
+
+	// a different secondary call is only used when the primary fails.
+	fallback, err := Fallback(mc,
+		panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", [20]byte{0xde, 0xad})),
+		panicIfError(DescribeSig[big.Int](token, "totalSupply()(uint256)")),
+	)
+	assert.NoError(t, err)
+	values, err := DoManyValues(mc, fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, *big.NewInt(1_000_000), values[0])
+}
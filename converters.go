@@ -0,0 +1,252 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/holiman/uint256"
+)
+
+// target type -> func(interface{}) (reflect.Value, error)
+var converters sync.Map
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+func init() {
+	RegisterConverter(func(value interface{}) (uint256.Int, error) {
+		v, ok := value.(*big.Int)
+		if !ok {
+			return uint256.Int{}, fmt.Errorf("cannot convert %T to uint256.Int", value)
+		}
+		if v.Sign() < 0 {
+			return uint256.Int{}, fmt.Errorf("%s is negative and doesn't fit in a uint256.Int", v)
+		}
+		out, overflow := uint256.FromBig(v)
+		if overflow {
+			return uint256.Int{}, fmt.Errorf("%s overflows uint256.Int", v)
+		}
+		return *out, nil
+	})
+
+	// timestamps are seconds since the epoch, as returned by `block.timestamp`.
+	RegisterConverter(func(value interface{}) (time.Time, error) {
+		seconds, err := convertTo(reflect.TypeOf(int64(0)), value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot convert %v to a timestamp: %w", value, err)
+		}
+		return time.Unix(seconds.Int(), 0).UTC(), nil
+	})
+}
+
+/*
+ * Teaches the decoder how to produce T from go-ethereum's decoded values (`*big.Int`, `common.Address`,
+ * `[32]byte`, anonymous tuple structs, ...). Once registered, `Describe[T]` and friends use it automatically,
+ * including for struct fields and slice elements of type T.
+ *
+ * Converters for `uint256.Int` and `time.Time` are built in. Registering a converter for a type replaces any
+ * previous one.
+ */
+func RegisterConverter[T any](convert func(value interface{}) (T, error)) {
+	converters.Store(reflect.TypeOf((*T)(nil)).Elem(), func(value interface{}) (reflect.Value, error) {
+		out, err := convert(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&out).Elem(), nil
+	})
+}
+
+/*
+ * convertTo converts a value decoded by go-ethereum into the target type. Compared to abi.ConvertType, it:
+ *	- uses registered converters,
+ *	- narrows integers (including `uint8`-backed Go enums) with overflow checks instead of truncating,
+ *	- maps tuples onto structs by field name or `abi:"name"` tag, so domain structs may rename or reorder fields,
+ *	- converts slices and arrays element by element.
+ */
+func convertTo(target reflect.Type, value interface{}) (out reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			out = reflect.Value{}
+			err = fmt.Errorf("cannot convert %T to %s: %v", value, target, r)
+		}
+	}()
+
+	if convert, ok := converters.Load(target); ok {
+		return convert.(func(interface{}) (reflect.Value, error))(value)
+	}
+	if value == nil {
+		return reflect.Value{}, fmt.Errorf("cannot convert nil to %s", target)
+	}
+
+	src := reflect.ValueOf(value)
+	switch {
+	case src.Type().AssignableTo(target):
+		return src, nil
+	case src.Kind() == reflect.Pointer && !src.IsNil() && src.Elem().Type().AssignableTo(target):
+		return src.Elem(), nil
+	case isInteger(target) && target != bigIntType:
+		return convertInteger(target, value)
+	case target.Kind() == reflect.Pointer:
+		elem, err := convertTo(target.Elem(), value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(target.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case (target.Kind() == reflect.Slice || target.Kind() == reflect.Array) && (src.Kind() == reflect.Slice || src.Kind() == reflect.Array) && !src.Type().ConvertibleTo(target):
+		return convertList(target, src)
+	case src.Type().ConvertibleTo(target):
+		return src.Convert(target), nil
+	case target.Kind() == reflect.Struct && src.Kind() == reflect.Struct:
+		names, values := tupleFields(src)
+		result := reflect.New(target).Elem()
+		if err := assignFields(result, names, values); err != nil {
+			return reflect.Value{}, err
+		}
+		return result, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", value, target)
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func convertInteger(target reflect.Type, value interface{}) (reflect.Value, error) {
+	var n *big.Int
+	src := reflect.ValueOf(value)
+	switch {
+	case src.Type() == reflect.TypeOf(&big.Int{}):
+		n = value.(*big.Int)
+	case src.CanInt():
+		n = big.NewInt(src.Int())
+	case src.CanUint():
+		n = new(big.Int).SetUint64(src.Uint())
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", value, target)
+	}
+
+	out := reflect.New(target).Elem()
+	bits := uint(target.Bits())
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !n.IsInt64() || out.OverflowInt(n.Int64()) {
+			return reflect.Value{}, fmt.Errorf("%s overflows %s (%d bits)", n, target, bits)
+		}
+		out.SetInt(n.Int64())
+	default:
+		if n.Sign() < 0 || !n.IsUint64() || out.OverflowUint(n.Uint64()) {
+			return reflect.Value{}, fmt.Errorf("%s overflows %s (%d bits)", n, target, bits)
+		}
+		out.SetUint(n.Uint64())
+	}
+	return out, nil
+}
+
+func convertList(target reflect.Type, src reflect.Value) (reflect.Value, error) {
+	var out reflect.Value
+	if target.Kind() == reflect.Slice {
+		out = reflect.MakeSlice(target, src.Len(), src.Len())
+	} else {
+		if src.Len() != target.Len() {
+			return reflect.Value{}, fmt.Errorf("cannot convert %d elements to %s", src.Len(), target)
+		}
+		out = reflect.New(target).Elem()
+	}
+	for i := 0; i < src.Len(); i++ {
+		elem, err := convertTo(target.Elem(), src.Index(i).Interface())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+		}
+		out.Index(i).Set(elem)
+	}
+	return out, nil
+}
+
+// tupleFields lists the fields of a struct decoded by go-ethereum, by their original ABI name when known.
+func tupleFields(src reflect.Value) ([]string, []interface{}) {
+	names := []string{}
+	values := []interface{}{}
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			name = tag
+		}
+		names = append(names, name)
+		values = append(values, src.Field(i).Interface())
+	}
+	return names, values
+}
+
+/*
+ * assignFields stores values into the exported fields of the struct `target`. Each value goes to the field tagged
+ * `abi:"<name>"`, else the field whose name matches case-insensitively; when any name can't be matched, values
+ * are assigned by position instead.
+ */
+func assignFields(target reflect.Value, names []string, values []interface{}) error {
+	fields := exportedFields(target.Type())
+	indices, ok := matchFields(target.Type(), fields, names)
+	if !ok {
+		if len(fields) < len(values) {
+			return fmt.Errorf("%d values can't be stored in %s, which only has %d exported fields", len(values), target.Type(), len(fields))
+		}
+		indices = fields[:len(values)]
+	}
+
+	for i, value := range values {
+		field := target.Field(indices[i])
+		converted, err := convertTo(field.Type(), value)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", target.Type().Field(indices[i]).Name, err)
+		}
+		field.Set(converted)
+	}
+	return nil
+}
+
+func matchFields(t reflect.Type, fields []int, names []string) ([]int, bool) {
+	indices := make([]int, len(names))
+	used := map[int]bool{}
+	for i, name := range names {
+		if name == "" {
+			return nil, false
+		}
+		found := -1
+		for _, f := range fields {
+			if tag := t.Field(f).Tag.Get("abi"); tag == name {
+				found = f
+				break
+			}
+		}
+		if found < 0 {
+			for _, f := range fields {
+				if t.Field(f).Tag.Get("abi") == "" && strings.EqualFold(t.Field(f).Name, abi.ToCamelCase(name)) {
+					found = f
+					break
+				}
+			}
+		}
+		if found < 0 || used[found] {
+			return nil, false
+		}
+		used[found] = true
+		indices[i] = found
+	}
+	return indices, true
+}
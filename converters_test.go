@@ -0,0 +1,156 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+const poolTestAbi = `[
+	{"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"hugeSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"status","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"lastUpdated","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint64"}]},
+	{"type":"function","name":"price","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"position","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"tuple","components":[
+		{"name":"owner","type":"address"},{"name":"liquidity","type":"uint128"},{"name":"openedAt","type":"uint256"}]}]},
+	{"type":"function","name":"reserves","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},{"name":"blockTimestampLast","type":"uint32"}]}
+]`
+
+var hugeSupply, _ = new(big.Int).SetString("100000000000000000000000000000", 10)
+
+func fakePool() fakeContract {
+	parsed := mustParseABIString(poolTestAbi)
+	constant := func(values ...interface{}) fakeMethod {
+		return func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return values, nil
+		}
+	}
+	return abiContract(parsed, map[string]fakeMethod{
+		"totalSupply": constant(big.NewInt(1000)),
+		"hugeSupply":  constant(hugeSupply),
+		"status":      constant(uint8(2)),
+		"lastUpdated": constant(uint64(1700000000)),
+		// 1.5, with 18 decimals
+		"price": constant(big.NewInt(1500000000000000000)),
+		"position": constant(struct {
+			Owner     common.Address `json:"owner"`
+			Liquidity *big.Int       `json:"liquidity"`
+			OpenedAt  *big.Int       `json:"openedAt"`
+		}{fakeAddress(0x0e), big.NewInt(77), big.NewInt(1600000000)}),
+		"reserves": constant(big.NewInt(10), big.NewInt(20), uint32(1700000000)),
+	})
+}
+
+type poolStatus uint8
+
+const (
+	poolInactive poolStatus = iota
+	poolActive
+	poolPaused
+)
+
+// a toy fixed-point type, standing in for a decimal library.
+type wad struct {
+	units *big.Int
+}
+
+func (w wad) String() string {
+	whole, frac := new(big.Int).QuoRem(w.units, big.NewInt(1e18), new(big.Int))
+	return fmt.Sprintf("%s.%018s", whole, frac)
+}
+
+type poolPosition struct {
+	Holder    common.Address `abi:"owner"`
+	Liquidity uint64
+	Opened    time.Time `abi:"openedAt"`
+}
+
+type poolReserves struct {
+	Updated  time.Time `abi:"blockTimestampLast"`
+	Reserve0 uint64
+	Reserve1 uint256.Int
+}
+
+func TestConverters(t *testing.T) {
+	RegisterConverter(func(value interface{}) (wad, error) {
+		units, ok := value.(*big.Int)
+		if !ok {
+			return wad{}, fmt.Errorf("cannot convert %T to wad", value)
+		}
+		return wad{units: units}, nil
+	})
+
+	chain := newFakeChain()
+	pool := fakeAddress(0x90)
+	chain.contracts[pool] = fakePool()
+	mc := newFakeClient(t, chain)
+	poolAbi := mustParseABI(t, poolTestAbi)
+
+	supply, _ := Describe[uint256.Int](pool, poolAbi, "totalSupply")
+	supply64, _ := Describe[uint64](pool, poolAbi, "totalSupply")
+	huge64, _ := Describe[uint64](pool, poolAbi, "hugeSupply")
+	status, _ := Describe[poolStatus](pool, poolAbi, "status")
+	updated, _ := Describe[time.Time](pool, poolAbi, "lastUpdated")
+	price, _ := Describe[wad](pool, poolAbi, "price")
+	position, _ := Describe[poolPosition](pool, poolAbi, "position")
+	reserves, _ := Describe[poolReserves](pool, poolAbi, "reserves")
+
+	assert.Equal(t, *uint256.NewInt(1000), resultsFor(t, mc, supply)[0].Value)
+	assert.Equal(t, uint64(1000), resultsFor(t, mc, supply64)[0].Value)
+
+	// uint256 outputs that don't fit are reported rather than truncated.
+	overflow := resultsFor(t, mc, huge64)[0]
+	assert.False(t, overflow.Ok())
+	assert.ErrorContains(t, overflow.Err(), "overflows uint64")
+
+	assert.Equal(t, poolPaused, resultsFor(t, mc, status)[0].Value)
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), resultsFor(t, mc, updated)[0].Value)
+	assert.Equal(t, "1.500000000000000000", resultsFor(t, mc, price)[0].Value.String())
+
+	assert.Equal(t, poolPosition{
+		Holder:    fakeAddress(0x0e),
+		Liquidity: 77,
+		Opened:    time.Unix(1600000000, 0).UTC(),
+	}, resultsFor(t, mc, position)[0].Value)
+
+	assert.Equal(t, poolReserves{
+		Updated:  time.Unix(1700000000, 0).UTC(),
+		Reserve0: 10,
+		Reserve1: *uint256.NewInt(20),
+	}, resultsFor(t, mc, reserves)[0].Value)
+}
+
+func TestConvertTo(t *testing.T) {
+	_, err := convertValue[uint8](big.NewInt(256))
+	assert.ErrorContains(t, err, "overflows")
+	_, err = convertValue[int8](big.NewInt(-129))
+	assert.Error(t, err)
+	_, err = convertValue[uint64](big.NewInt(-1))
+	assert.Error(t, err)
+	_, err = convertValue[uint256.Int](new(big.Int).Lsh(big.NewInt(1), 256))
+	assert.Error(t, err)
+
+	small, err := convertValue[[]uint16]([]*big.Int{big.NewInt(1), big.NewInt(2)})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{1, 2}, *small)
+
+	hash, err := convertValue[common.Hash]([32]byte{1})
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{1}, *hash)
+
+	_, err = convertValue[common.Address]("nope")
+	assert.Error(t, err)
+}
+
+func resultsFor[A any](t *testing.T, mc *MulticallClient, call *MultiCallMetaData[A]) []Result[A] {
+	results, err := DoManyResults(mc, call)
+	assert.NoError(t, err)
+	return results
+}
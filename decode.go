@@ -0,0 +1,86 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// convertValue converts a single decoded value into T using `convertTo()`, reporting incompatible types as an error.
+func convertValue[T any](in interface{}) (*T, error) {
+	out := new(T)
+	if err := setConverted(reflect.ValueOf(out).Elem(), in); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+/*
+ * Unpacks the return data of a call into T.
+ *
+ * Single outputs are converted directly. When a method returns several values, T may be:
+ *	- []interface{}, which receives the raw unpacked values,
+ *	- a struct, which is filled by output name (or `abi:"name"` tag) when every output matches a field, and by
+ *	  position otherwise.
+ *
+ * Values are converted with any converter registered through `RegisterConverter()`.
+ */
+func unpackOutputs[T any](outputs abi.Arguments, data []byte) (*T, error) {
+	out := new(T)
+	if err := unpackInto(outputs, data, reflect.ValueOf(out).Elem()); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// unpackInto is `unpackOutputs()` for a target only known at runtime. `target` must be settable.
+func unpackInto(outputs abi.Arguments, data []byte, target reflect.Value) error {
+	values, err := outputs.Unpack(data)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return errors.New("method has no outputs")
+	}
+	if len(values) == 1 {
+		return setConverted(target, values[0])
+	}
+
+	if target.Type() == reflect.TypeOf([]interface{}{}) {
+		target.Set(reflect.ValueOf(values))
+		return nil
+	}
+	if target.Kind() != reflect.Struct {
+		return fmt.Errorf("method returns %d values, which can't be stored in %s", len(values), target.Type())
+	}
+
+	names := make([]string, len(outputs))
+	for i, output := range outputs {
+		names[i] = output.Name
+	}
+	if err := assignFields(target, names, values); err != nil {
+		return fmt.Errorf("method returns %d values: %w", len(values), err)
+	}
+	return nil
+}
+
+func setConverted(field reflect.Value, value interface{}) error {
+	converted, err := convertTo(field.Type(), value)
+	if err != nil {
+		return err
+	}
+	field.Set(converted)
+	return nil
+}
+
+func exportedFields(t reflect.Type) []int {
+	fields := []int{}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			fields = append(fields, i)
+		}
+	}
+	return fields
+}
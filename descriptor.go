@@ -0,0 +1,236 @@
+package multicall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/*
+ * A call that can be stored or sent elsewhere, unlike `MultiCallMetaData`, whose deserializer is a closure.
+ * `Compile()` turns it back into a call on the other end.
+ *
+ * The method is given either as a human-readable `Signature` (see `DescribeSig()`), or as an `ABI` fragment
+ * plus the `Method` name. `Args` are encoded as by `MarshalArgumentsJSON()`, `Value` is the msg.value sent
+ * with a payable call, and `From` the sender of a call made with `WithFrom()`.
+ */
+type CallDescriptor struct {
+	Target    common.Address   `json:"target"`
+	Signature string           `json:"signature,omitempty"`
+	ABI       json.RawMessage  `json:"abi,omitempty"`
+	Method    string           `json:"method,omitempty"`
+	Args      json.RawMessage  `json:"args,omitempty"`
+	Value     *big.Int         `json:"value,omitempty"`
+	From      *common.Address  `json:"from,omitempty"`
+	Block     *DescriptorBlock `json:"block,omitempty"`
+}
+
+// The block a `CallDescriptor` should be read at. Leave it nil for the latest block.
+type DescriptorBlock struct {
+	Number  *big.Int     `json:"number,omitempty"`
+	Hash    *common.Hash `json:"hash,omitempty"`
+	Pending bool         `json:"pending,omitempty"`
+}
+
+/*
+ * Builds a descriptor for a call made by `Describe()` or `DescribeSig()`, reading at the block of `options`
+ * (which may be nil).
+ */
+func NewCallDescriptor[T any](md *MultiCallMetaData[T], options *bind.CallOpts) (CallDescriptor, error) {
+	if md.Method == nil {
+		return CallDescriptor{}, fmt.Errorf("%s has no ABI to describe it with", md.FunctionName)
+	}
+	fragment, err := methodFragment(*md.Method)
+	if err != nil {
+		return CallDescriptor{}, err
+	}
+	args, err := md.Method.Inputs.Unpack(md.Data[4:])
+	if err != nil {
+		return CallDescriptor{}, fmt.Errorf("error unpacking arguments of %s: %s", md.FunctionName, err.Error())
+	}
+	encodedArgs, err := MarshalArgumentsJSON(md.Method.Inputs, args)
+	if err != nil {
+		return CallDescriptor{}, err
+	}
+
+	descriptor := CallDescriptor{
+		Target: md.Address,
+		ABI:    fragment,
+		Method: md.Method.RawName,
+		Args:   encodedArgs,
+		Value:  md.Value,
+		From:   md.From,
+	}
+	if options != nil && (options.BlockNumber != nil || options.BlockHash != (common.Hash{}) || options.Pending) {
+		descriptor.Block = &DescriptorBlock{Number: options.BlockNumber, Pending: options.Pending}
+		if options.BlockHash != (common.Hash{}) {
+			hash := options.BlockHash
+			descriptor.Block.Hash = &hash
+		}
+	}
+	return descriptor, nil
+}
+
+func (d *CallDescriptor) Marshal() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+func (d *CallDescriptor) Unmarshal(data []byte) error {
+	var out CallDescriptor
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	if out.Target == (common.Address{}) {
+		return errors.New("descriptor has no target")
+	}
+	if out.Signature == "" && (len(out.ABI) == 0 || out.Method == "") {
+		return errors.New("descriptor needs either a signature, or an abi and a method")
+	}
+	*d = out
+	return nil
+}
+
+// Rebuilds the call, returning its outputs by name like `DoManyDynamic()` does.
+func (d *CallDescriptor) Compile() (*MultiCallMetaData[[]NamedValue], error) {
+	contractAbi, method, args, err := d.resolve()
+	if err != nil {
+		return nil, err
+	}
+	outputs := contractAbi.Methods[method].Outputs
+	call, err := DescribeWithDeserialize(d.Target, contractAbi, func(b []byte) (*[]NamedValue, error) {
+		named, err := unpackNamed(outputs, b)
+		if err != nil {
+			return nil, err
+		}
+		return &named, nil
+	}, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	call.Value = d.Value
+	call.From = d.From
+	return call, nil
+}
+
+// Rebuilds the call with its outputs deserialized into T, as by `Describe()`.
+func CompileAs[T any](d *CallDescriptor) (*MultiCallMetaData[T], error) {
+	contractAbi, method, args, err := d.resolve()
+	if err != nil {
+		return nil, err
+	}
+	call, err := Describe[T](d.Target, contractAbi, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	call.Value = d.Value
+	call.From = d.From
+	return call, nil
+}
+
+// Returns `base` (which may be nil) set to read at the descriptor's block.
+func (d *CallDescriptor) CallOpts(base *bind.CallOpts) *bind.CallOpts {
+	opts := bind.CallOpts{}
+	if base != nil {
+		opts = *base
+	}
+	if d.Block != nil {
+		opts.BlockNumber = d.Block.Number
+		opts.Pending = d.Block.Pending
+		if d.Block.Hash != nil {
+			opts.BlockHash = *d.Block.Hash
+		}
+	}
+	return &opts
+}
+
+func (d *CallDescriptor) resolve() (abi.ABI, string, []interface{}, error) {
+	var contractAbi abi.ABI
+	var method string
+	switch {
+	case d.Signature != "":
+		parsed, err := parseSignatureCached(d.Signature)
+		if err != nil {
+			return abi.ABI{}, "", nil, err
+		}
+		contractAbi, method = parsed.abi, parsed.method.Name
+	case len(d.ABI) > 0:
+		parsed, err := abi.JSON(strings.NewReader(string(d.ABI)))
+		if err != nil {
+			return abi.ABI{}, "", nil, fmt.Errorf("invalid abi: %w", err)
+		}
+		if _, ok := parsed.Methods[d.Method]; !ok {
+			return abi.ABI{}, "", nil, fmt.Errorf("method %q not found in abi", d.Method)
+		}
+		contractAbi, method = parsed, d.Method
+	default:
+		return abi.ABI{}, "", nil, errors.New("descriptor needs either a signature, or an abi and a method")
+	}
+
+	inputs := contractAbi.Methods[method].Inputs
+	if len(d.Args) == 0 {
+		if len(inputs) > 0 {
+			return abi.ABI{}, "", nil, fmt.Errorf("%s takes %d arguments, but none were given", method, len(inputs))
+		}
+		return contractAbi, method, nil, nil
+	}
+	args, err := UnmarshalArgumentsJSON(inputs, d.Args)
+	if err != nil {
+		return abi.ABI{}, "", nil, fmt.Errorf("invalid arguments for %s: %w", method, err)
+	}
+	return contractAbi, method, args, nil
+}
+
+// methodFragment writes a single-method JSON ABI for m.
+func methodFragment(m abi.Method) (json.RawMessage, error) {
+	fragment := []map[string]interface{}{{
+		"type":            "function",
+		"name":            m.RawName,
+		"stateMutability": m.StateMutability,
+		"inputs":          argumentsMarshaling(m.Inputs),
+		"outputs":         argumentsMarshaling(m.Outputs),
+	}}
+	return json.Marshal(fragment)
+}
+
+type fragmentArgument struct {
+	Name       string             `json:"name"`
+	Type       string             `json:"type"`
+	Components []fragmentArgument `json:"components,omitempty"`
+}
+
+func argumentsMarshaling(args abi.Arguments) []fragmentArgument {
+	out := make([]fragmentArgument, len(args))
+	for i, arg := range args {
+		out[i] = typeMarshaling(arg.Name, arg.Type)
+	}
+	return out
+}
+
+// typeMarshaling describes t in the JSON ABI format, where tuples are spelled `tuple[]...` plus their components.
+func typeMarshaling(name string, t abi.Type) fragmentArgument {
+	suffix := ""
+	base := t
+	for base.T == abi.SliceTy || base.T == abi.ArrayTy {
+		if base.T == abi.SliceTy {
+			suffix = "[]" + suffix
+		} else {
+			suffix = fmt.Sprintf("[%d]", base.Size) + suffix
+		}
+		base = *base.Elem
+	}
+	if base.T != abi.TupleTy {
+		return fragmentArgument{Name: name, Type: t.String()}
+	}
+
+	out := fragmentArgument{Name: name, Type: "tuple" + suffix}
+	for i, elem := range base.TupleElems {
+		out.Components = append(out.Components, typeMarshaling(base.TupleRawNames[i], *elem))
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallDescriptor(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x70)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+
+	call, err := Describe[big.Int](token, mustParseABI(t, erc20TestAbi), "balanceOf", fakeAddress(0x1234))
+	assert.NoError(t, err)
+	descriptor, err := NewCallDescriptor(call, &bind.CallOpts{BlockNumber: big.NewInt(90)})
+	assert.NoError(t, err)
+
+	// the descriptor survives a trip through a queue as plain JSON.
+	encoded, err := descriptor.Marshal()
+	assert.NoError(t, err)
+	var received CallDescriptor
+	assert.NoError(t, received.Unmarshal(encoded))
+
+	typed, err := CompileAs[big.Int](&received)
+	assert.NoError(t, err)
+	assert.Equal(t, call.Data, typed.Data)
+	assert.Equal(t, big.NewInt(90), received.CallOpts(nil).BlockNumber)
+
+	values, err := DoManyValuesWithOptions(mc, received.CallOpts(nil), typed)
+	assert.NoError(t, err)
+	assert.Equal(t, *fakeBalance(fakeAddress(0x1234)), values[0])
+
+	dynamic, err := received.Compile()
+	assert.NoError(t, err)
+	named, err := DoManyValues(mc, dynamic)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeBalance(fakeAddress(0x1234)), named[0][0].Value)
+
+	// descriptors can also be written by hand, with a signature instead of an ABI.
+	var handwritten CallDescriptor
+	assert.NoError(t, handwritten.Unmarshal([]byte(`{
+		"target":"`+token.Hex()+`",
+		"signature":"balanceOf(address)(uint256)",
+		"args":["`+fakeAddress(0x1234).Hex()+`"]
+	}`)))
+	fromSig, err := CompileAs[uint64](&handwritten)
+	assert.NoError(t, err)
+	assert.Equal(t, call.Data, fromSig.Data)
+
+	assert.Error(t, new(CallDescriptor).Unmarshal([]byte(`{"target":"`+token.Hex()+`"}`)))
+	assert.Error(t, new(CallDescriptor).Unmarshal([]byte(`{"signature":"symbol()(string)"}`)))
+	_, err = (&CallDescriptor{Target: token, Signature: "balanceOf(address)(uint256)"}).Compile()
+	assert.Error(t, err)
+}
+
+func TestCallDescriptor_Tuples(t *testing.T) {
+	ledgerAbi := mustParseABI(t, ledgerTestAbi)
+	call, err := Describe[ledgerEntry](fakeAddress(0x10), ledgerAbi, "entry", fakeAddress(0x0e), []*big.Int{big.NewInt(1)})
+	assert.NoError(t, err)
+	descriptor, err := NewCallDescriptor(call, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, descriptor.Block)
+
+	compiled, err := CompileAs[ledgerEntry](&descriptor)
+	assert.NoError(t, err)
+	assert.Equal(t, call.Data, compiled.Data)
+	assert.Equal(t, call.Method.Outputs[0].Type.String(), compiled.Method.Outputs[0].Type.String())
+	assert.Equal(t, call.Method.Outputs[0].Type.TupleRawNames, compiled.Method.Outputs[0].Type.TupleRawNames)
+}
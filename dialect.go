@@ -0,0 +1,204 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// The interface of a multicall contract. Results have the same semantics whichever one is deployed.
+type Dialect int
+
+const (
+	// aggregate3, with per-call failures and values.
+	DialectMulticall3 Dialect = iota
+	// tryAggregate(false, calls), which reports per-call failures but can't send values or nest calls.
+	DialectMulticall2
+	/*
+	 * aggregate(calls), which reverts as a whole when any call fails. A reverting chunk is bisected until the
+	 * failing calls are isolated, and each of those is then made on its own to recover its revert data.
+	 */
+	DialectMulticall1
+)
+
+// The Ethereum mainnet deployments of Multicall2 and Multicall1, used when no address is passed for those dialects.
+const defaultMulticall2Address = "0x5BA1e12693Dc8F9c48aAD8770482f4739bEeD696"
+const defaultMulticall1Address = "0xeefBa1e63905eF1D7ACbA5a8513c70307C1cE441"
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectMulticall3:
+		return "Multicall3"
+	case DialectMulticall2:
+		return "Multicall2"
+	case DialectMulticall1:
+		return "Multicall1"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+func (d Dialect) defaultAddress() common.Address {
+	switch d {
+	case DialectMulticall2:
+		return common.HexToAddress(defaultMulticall2Address)
+	case DialectMulticall1:
+		return common.HexToAddress(defaultMulticall1Address)
+	}
+	return common.HexToAddress(defaultMulticallAddress)
+}
+
+// aggregator returns the aggregator speaking the client's dialect.
+func (mc *MulticallClient) aggregator() aggregator {
+	switch mc.Dialect {
+	case DialectMulticall2:
+		return mc.tryAggregate
+	case DialectMulticall1:
+		return mc.aggregate
+	}
+	return mc.aggregate3
+}
+
+func (mc *MulticallClient) tryAggregate(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+	if totalValue(calls).Sign() > 0 {
+		return nil, fmt.Errorf("%s can't send values", mc.Dialect)
+	}
+	var res []interface{}
+	err := mc.Contract.Call(opts, &res, "tryAggregate", false, plainCalls(calls))
+	if err != nil {
+		return nil, fmt.Errorf("tryAggregate failed: %w", err)
+	}
+	return *abi.ConvertType(res[0], new([]Multicall3Result)).(*[]Multicall3Result), nil
+}
+
+func (mc *MulticallClient) aggregate(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+	if totalValue(calls).Sign() > 0 {
+		return nil, fmt.Errorf("%s can't send values", mc.Dialect)
+	}
+	results, err := mc.tryWholeAggregate(opts, calls)
+	if err == nil || !isRevert(err) {
+		return results, err
+	}
+
+	// bisect at a fixed block, so that the results of every part are consistent.
+	pinned, err := mc.pinBlockNumber(opts)
+	if err != nil {
+		return nil, err
+	}
+	return mc.bisectAggregate(pinned, calls)
+}
+
+// pinBlockNumber makes sure every read made with the returned options hits the same block, by asking the node for
+// the current block number when the caller didn't ask for a specific block.
+func (mc *MulticallClient) pinBlockNumber(opts *bind.CallOpts) (*bind.CallOpts, error) {
+	pinned := bind.CallOpts{}
+	if opts != nil {
+		pinned = *opts
+	}
+	if pinned.BlockNumber != nil || pinned.Pending || pinned.BlockHash != (common.Hash{}) {
+		return &pinned, nil
+	}
+	if mc.Client == nil {
+		return nil, ErrNoClient
+	}
+	number, err := mc.Client.BlockNumber(callContext(mc, opts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pin block: %s", err.Error())
+	}
+	pinned.BlockNumber = new(big.Int).SetUint64(number)
+	return &pinned, nil
+}
+
+// bisectAggregate isolates the calls that make `aggregate` revert, reporting them as failed results.
+func (mc *MulticallClient) bisectAggregate(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+	results, err := mc.tryWholeAggregate(opts, calls)
+	if err == nil || !isRevert(err) {
+		return results, err
+	}
+	if len(calls) == 1 {
+		revertData, err := mc.revertData(opts, calls[0])
+		if err != nil {
+			return nil, err
+		}
+		return []Multicall3Result{{Success: false, ReturnData: revertData}}, nil
+	}
+
+	left, err := mc.bisectAggregate(opts, calls[:len(calls)/2])
+	if err != nil {
+		return nil, err
+	}
+	right, err := mc.bisectAggregate(opts, calls[len(calls)/2:])
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+func (mc *MulticallClient) tryWholeAggregate(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+	var res []interface{}
+	err := mc.Contract.Call(opts, &res, "aggregate", plainCalls(calls))
+	if err != nil {
+		return nil, fmt.Errorf("aggregate failed: %w", err)
+	}
+	return mapCollection(res[1].([][]byte), func(data []byte, _ uint64) Multicall3Result {
+		return Multicall3Result{Success: true, ReturnData: data}
+	}), nil
+}
+
+// revertData makes a failing call on its own, from the multicall contract, to find out what it reverts with.
+func (mc *MulticallClient) revertData(opts *bind.CallOpts, call ParamMulticall3Call3) ([]byte, error) {
+	if mc.Client == nil {
+		return nil, nil
+	}
+	_, err := mc.callAt(opts, ethereum.CallMsg{From: mc.Address, To: &call.Target, Data: call.CallData})
+	if err == nil {
+		// the call only fails within the batch, e.g. because it runs out of gas.
+		return nil, nil
+	}
+	if !isRevert(err) {
+		return nil, fmt.Errorf("failed to make call to %s: %s", call.Target, err.Error())
+	}
+	return revertDataOf(err), nil
+}
+
+// revertDataOf extracts the data an eth_call reverted with from its error, if the node reported any.
+func revertDataOf(err error) []byte {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+	encoded, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil
+	}
+	data, err := hexutil.Decode(encoded)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func isRevert(err error) bool {
+	var dataErr rpc.DataError
+	return errors.As(err, &dataErr) || strings.Contains(err.Error(), "execution reverted")
+}
+
+// The `Call` struct of Multicall1 and Multicall2.
+type paramMulticallCall struct {
+	Target   common.Address
+	CallData []byte
+}
+
+// plainCalls drops the fields Multicall1 and Multicall2's `Call` struct doesn't have.
+func plainCalls(calls []ParamMulticall3Call3) []paramMulticallCall {
+	return mapCollection(calls, func(call ParamMulticall3Call3, _ uint64) paramMulticallCall {
+		return paramMulticallCall{Target: call.Target, CallData: call.CallData}
+	})
+}
@@ -0,0 +1,180 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLegacyMulticall is Multicall3 stripped down to the methods an older deployment has.
+func fakeLegacyMulticall(methods ...string) fakeContract {
+	parsed := panicIfError(abi.JSON(strings.NewReader(multicallAbi)))
+	multicall3 := fakeMulticall3()
+	return func(ctx fakeCallContext, data []byte) ([]byte, error) {
+		if len(data) < 4 {
+			return nil, &fakeRevert{}
+		}
+		method, err := parsed.MethodById(data[:4])
+		if err != nil {
+			return nil, &fakeRevert{}
+		}
+		for _, name := range methods {
+			if name == method.Name {
+				return multicall3(ctx, data)
+			}
+		}
+		return nil, &fakeRevert{}
+	}
+}
+
+func newDialectClient(t *testing.T, dialect Dialect, methods ...string) (*fakeChain, *MulticallClient) {
+	chain := newFakeChain()
+	chain.contracts[dialect.defaultAddress()] = fakeLegacyMulticall(methods...)
+	chain.contracts[fakeAddress(0xa0)] = fakeERC20("A", 18)
+	mc := newFakeClient(t, chain)
+	legacy, err := NewMulticallClient(context.Background(), mc.Client, &TMulticallClientOptions{Dialect: dialect})
+	assert.NoError(t, err)
+	assert.Equal(t, dialect.defaultAddress(), legacy.Address)
+	// the real Multicall3 is still deployed on the fake chain, so make sure it isn't what answers.
+	delete(chain.contracts, common.HexToAddress(defaultMulticallAddress))
+	return chain, legacy
+}
+
+func balanceCalls(t *testing.T, holders ...common.Address) []*MultiCallMetaData[big.Int] {
+	erc20 := mustParseABI(t, erc20TestAbi)
+	return mapCollection(holders, func(holder common.Address, _ uint64) *MultiCallMetaData[big.Int] {
+		call, err := Describe[big.Int](fakeAddress(0xa0), erc20, "balanceOf", holder)
+		assert.NoError(t, err)
+		return call
+	})
+}
+
+func TestDialects(t *testing.T) {
+	blocked := common.HexToAddress("0xdead000000000000000000000000000000000001")
+	holders := []common.Address{fakeAddress(1), blocked, fakeAddress(3), fakeAddress(4), blocked}
+
+	for _, tc := range []struct {
+		dialect Dialect
+		methods []string
+	}{
+		{DialectMulticall2, []string{"tryAggregate", "aggregate", "tryBlockAndAggregate", "getBlockNumber"}},
+		{DialectMulticall1, []string{"aggregate", "getBlockNumber"}},
+	} {
+		t.Run(tc.dialect.String(), func(t *testing.T) {
+			_, mc := newDialectClient(t, tc.dialect, tc.methods...)
+
+			results, err := DoManyResults(mc, balanceCalls(t, holders...)...)
+			assert.NoError(t, err)
+			assert.Len(t, results, len(holders))
+			for i, holder := range holders {
+				if holder == blocked {
+					var revert *RevertError
+					assert.ErrorAs(t, results[i].Err(), &revert)
+					assert.Equal(t, "blocked holder", revert.Reason)
+					continue
+				}
+				assert.Equal(t, *fakeBalance(holder), results[i].Value)
+			}
+
+			number, err := DoManyValues(mc, mc.GetBlockNumber())
+			assert.NoError(t, err)
+			assert.Equal(t, "100", number[0].String())
+
+			deposit := balanceCalls(t, holders[0])[0].WithValue(big.NewInt(1))
+			_, err = DoManyResults(mc, deposit)
+			assert.Error(t, err)
+			_, err = Zip(mc, deposit, deposit)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMulticall1Bisection(t *testing.T) {
+	chain, mc := newDialectClient(t, DialectMulticall1, "aggregate")
+	blocked := common.HexToAddress("0xdead000000000000000000000000000000000001")
+
+	chain.ethCalls = 0
+	values, err := DoManyValues(mc, balanceCalls(t, fakeAddress(1), fakeAddress(2), fakeAddress(3), fakeAddress(4))...)
+	assert.NoError(t, err)
+	assert.Len(t, values, 4)
+	assert.Equal(t, 1, chain.ethCalls)
+
+	// one failure among eight calls: the whole batch, then halves down to the call, then the call on its own.
+	chain.ethCalls = 0
+	holders := []common.Address{fakeAddress(1), fakeAddress(2), fakeAddress(3), fakeAddress(4), fakeAddress(5), blocked, fakeAddress(7), fakeAddress(8)}
+	results, err := DoManyResults(mc, balanceCalls(t, holders...)...)
+	assert.NoError(t, err)
+	assert.Equal(t, 1+1+2+2+2+1, chain.ethCalls)
+	for i, res := range results {
+		assert.Equal(t, i != 5, res.Ok(), i)
+	}
+	assert.Equal(t, *fakeBalance(holders[7]), results[7].Value)
+
+	_, err = DoManyAttested(mc, balanceCalls(t, holders[0])...)
+	assert.Error(t, err)
+}
+
+// The methods of the real Multicall1 and Multicall2 deployments.
+var (
+	multicall1Methods = []string{"aggregate", "getEthBalance", "getBlockHash", "getLastBlockHash", "getCurrentBlockTimestamp",
+		"getCurrentBlockDifficulty", "getCurrentBlockGasLimit", "getCurrentBlockCoinbase"}
+	multicall2Methods = append([]string{"tryAggregate", "tryBlockAndAggregate", "blockAndAggregate", "getBlockNumber"}, multicall1Methods...)
+)
+
+func TestDialects_PinnedReads(t *testing.T) {
+	for _, tc := range []struct {
+		dialect Dialect
+		methods []string
+	}{
+		{DialectMulticall2, multicall2Methods},
+		{DialectMulticall1, multicall1Methods},
+	} {
+		t.Run(tc.dialect.String(), func(t *testing.T) {
+			chain, mc := newDialectClient(t, tc.dialect, tc.methods...)
+			factory := fakeAddress(0xfac)
+			chain.contracts[factory] = fakeFactory(3)
+			factoryAbi := mustParseABI(t, factoryTestAbi)
+
+			// the block is pinned with the node, not with Multicall3's getBlockNumber().
+			length, err := Describe[uint64](factory, factoryAbi, "allPairsLength")
+			assert.NoError(t, err)
+			pairs, err := Enumerate(mc, length, func(i uint64) (*MultiCallMetaData[common.Address], error) {
+				return Describe[common.Address](factory, factoryAbi, "allPairs", new(big.Int).SetUint64(i))
+			}, nil)
+			assert.NoError(t, err)
+			assert.Len(t, pairs, 3)
+
+			p := NewPipeline(mc)
+			balances := AddStage(p, func() ([]*MultiCallMetaData[big.Int], error) {
+				return balanceCalls(t, fakeAddress(1)), nil
+			})
+			assert.NoError(t, p.Run())
+			values, err := balances.Values()
+			assert.NoError(t, err)
+			assert.Equal(t, *fakeBalance(fakeAddress(1)), values[0])
+
+			block := chain.blockNumber
+			snapshot, err := SnapshotContract(mc, factory, factoryAbi)
+			assert.NoError(t, err)
+			assert.Equal(t, big.NewInt(int64(block)), snapshot.Block)
+		})
+	}
+}
+
+func TestDialects_BlockContext(t *testing.T) {
+	_, mc := newDialectClient(t, DialectMulticall2, multicall2Methods...)
+	block, err := mc.BlockContext()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), block.Number)
+	assert.Nil(t, block.Basefee)
+	assert.Nil(t, block.ChainId)
+
+	_, mc = newDialectClient(t, DialectMulticall1, multicall1Methods...)
+	_, err = mc.BlockContext()
+	assert.ErrorContains(t, err, "unsupported by Multicall1")
+}
@@ -0,0 +1,130 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// A call whose types are only known at runtime.
+type DynamicCall struct {
+	Address common.Address
+	Method  abi.Method
+	Args    []interface{}
+	// Custom errors the method may revert with, used to decode failures. Optional.
+	Errors []abi.Error
+}
+
+// A single decoded output. Unnamed outputs are named after their position ("0", "1", ...).
+type NamedValue struct {
+	Name  string
+	Type  abi.Type
+	Value interface{}
+}
+
+// The outputs of a `DynamicCall`, in ABI order, or the reason it failed.
+type DynamicResult struct {
+	Outputs []NamedValue
+	Err     error
+}
+
+// Returns the outputs keyed by name.
+func (r DynamicResult) Map() map[string]interface{} {
+	out := make(map[string]interface{}, len(r.Outputs))
+	for _, output := range r.Outputs {
+		out[output.Name] = output.Value
+	}
+	return out
+}
+
+// Returns the output with the given name.
+func (r DynamicResult) Get(name string) (interface{}, bool) {
+	for _, output := range r.Outputs {
+		if output.Name == name {
+			return output.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Builds a `DynamicCall` for a method of an ABI loaded at runtime.
+func NewDynamicCall(contractAddress common.Address, contractAbi abi.ABI, method string, params ...interface{}) (DynamicCall, error) {
+	m, ok := contractAbi.Methods[method]
+	if !ok {
+		return DynamicCall{}, fmt.Errorf("method %q not found in abi", method)
+	}
+	return DynamicCall{Address: contractAddress, Method: m, Args: params, Errors: abiErrors(contractAbi)}, nil
+}
+
+// Executes calls whose Go types aren't known at compile time, returning every output by name.
+func DoManyDynamic(mc *MulticallClient, calls []DynamicCall) ([]DynamicResult, error) {
+	return DoManyDynamicWithOptions(mc, nil, calls)
+}
+
+func DoManyDynamicWithOptions(mc *MulticallClient, options *bind.CallOpts, calls []DynamicCall) ([]DynamicResult, error) {
+	raw := make([]RawMulticall, len(calls))
+	for i, call := range calls {
+		described, err := call.Raw()
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i, err)
+		}
+		raw[i] = described
+	}
+
+	res, err := doMultiCallMany(mc, options, raw...)
+	if err != nil {
+		return nil, fmt.Errorf("multicall failed: %s", err.Error())
+	}
+
+	return mapCollection(res, func(d DeserializedMulticall3Result, i uint64) DynamicResult {
+		if d.Success {
+			return DynamicResult{Outputs: d.Value.([]NamedValue)}
+		}
+		err, _ := d.Value.(error)
+		var revert *RevertError
+		if errors.As(err, &revert) {
+			err = DecodeRevert(revert.Data, calls[i].Errors...)
+		}
+		if err == nil {
+			err = ErrCallFailed
+		}
+		return DynamicResult{Err: err}
+	}), nil
+}
+
+// Describes the call for use with the rest of the package; its values are `[]NamedValue`.
+func (call DynamicCall) Raw() (RawMulticall, error) {
+	packed, err := call.Method.Inputs.Pack(call.Args...)
+	if err != nil {
+		return RawMulticall{}, fmt.Errorf("error packing multicall: %s", err.Error())
+	}
+	outputs := call.Method.Outputs
+	return RawMulticall{
+		Address:      call.Address,
+		Data:         append(append([]byte{}, call.Method.ID...), packed...),
+		FunctionName: call.Method.Name,
+		Deserialize: func(data []byte) (any, error) {
+			return unpackNamed(outputs, data)
+		},
+	}, nil
+}
+
+func unpackNamed(outputs abi.Arguments, data []byte) ([]NamedValue, error) {
+	values, err := outputs.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	named := make([]NamedValue, len(values))
+	for i, value := range values {
+		name := outputs[i].Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		named[i] = NamedValue{Name: name, Type: outputs[i].Type, Value: value}
+	}
+	return named, nil
+}
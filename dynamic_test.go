@@ -0,0 +1,91 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const vaultTestAbi = `[
+	{"type":"function","name":"ilks","stateMutability":"view","inputs":[{"name":"","type":"bytes32"}],"outputs":[
+		{"name":"Art","type":"uint256"},{"name":"rate","type":"uint256"},{"name":"spot","type":"uint256"},{"name":"line","type":"uint256"},{"name":"dust","type":"uint256"}]},
+	{"type":"function","name":"owner","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"secret","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"error","name":"Unauthorized","inputs":[{"name":"caller","type":"address"}]}
+]`
+
+func fakeVault(t *testing.T) fakeContract {
+	parsed := mustParseABI(t, vaultTestAbi)
+	return abiContract(parsed, map[string]fakeMethod{
+		"ilks": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			ilk := args[0].([32]byte)
+			return []interface{}{big.NewInt(int64(ilk[0])), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}, nil
+		},
+		"owner": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{fakeAddress(0x0e)}, nil
+		},
+		"secret": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			unauthorized := parsed.Errors["Unauthorized"]
+			encoded, _ := unauthorized.Inputs.Pack(ctx.from)
+			return nil, &fakeRevert{data: append(unauthorized.ID[:4], encoded...)}
+		},
+	})
+}
+
+func TestDoManyDynamic(t *testing.T) {
+	chain := newFakeChain()
+	vault := fakeAddress(0x7a)
+	chain.contracts[vault] = fakeVault(t)
+	mc := newFakeClient(t, chain)
+	vaultAbi := mustParseABI(t, vaultTestAbi)
+
+	ilks, err := NewDynamicCall(vault, vaultAbi, "ilks", [32]byte{9})
+	assert.NoError(t, err)
+	owner, err := NewDynamicCall(vault, vaultAbi, "owner")
+	assert.NoError(t, err)
+	secret, err := NewDynamicCall(vault, vaultAbi, "secret")
+	assert.NoError(t, err)
+
+	results, err := DoManyDynamic(mc, []DynamicCall{ilks, owner, secret})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, []string{"Art", "rate", "spot", "line", "dust"}, mapCollection(results[0].Outputs, func(v NamedValue, _ uint64) string { return v.Name }))
+	assert.Equal(t, big.NewInt(9), results[0].Map()["Art"])
+	dust, ok := results[0].Get("dust")
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(5), dust)
+
+	assert.Equal(t, fakeAddress(0x0e), results[1].Map()["0"])
+
+	// custom errors from the ABI are decoded.
+	var revert *RevertError
+	assert.True(t, errors.As(results[2].Err, &revert))
+	assert.ErrorIs(t, results[2].Err, ErrCallFailed)
+	assert.Equal(t, "Unauthorized", revert.Name)
+	assert.Equal(t, []interface{}{common.HexToAddress(defaultMulticallAddress)}, revert.Args)
+
+	_, err = NewDynamicCall(vault, vaultAbi, "missing")
+	assert.Error(t, err)
+	_, err = DoManyDynamic(mc, []DynamicCall{{Address: vault, Method: vaultAbi.Methods["ilks"], Args: []interface{}{"wrong"}}})
+	assert.Error(t, err)
+}
+
+func TestDecodeRevert(t *testing.T) {
+	revert := DecodeRevert(fakeRevertData(revertWithReason("not enough")))
+	assert.Equal(t, "Error", revert.Name)
+	assert.Equal(t, "not enough", revert.Reason)
+	assert.EqualError(t, revert, "call failed: not enough")
+
+	panicData := append(common.FromHex("0x4e487b71"), common.LeftPadBytes([]byte{0x11}, 32)...)
+	revert = DecodeRevert(panicData)
+	assert.Equal(t, "Panic", revert.Name)
+	assert.Contains(t, revert.Reason, "overflow")
+
+	assert.EqualError(t, DecodeRevert(nil), "call failed")
+	assert.EqualError(t, DecodeRevert([]byte{1, 2, 3, 4}), "call failed: 0x01020304")
+}
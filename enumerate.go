@@ -0,0 +1,86 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// Controls which items `Enumerate()` reads, and how.
+type EnumerateOptions struct {
+	CallOpts *bind.CallOpts
+	// The index of the first item to read.
+	Offset uint64
+	// The maximum number of items to read. 0 reads everything after `Offset`.
+	Limit uint64
+	// The number of items read per multicall, which is then chunked by the client's MaxBatchSize.
+	// Defaults to `DefaultEnumeratePageSize`.
+	PageSize uint64
+}
+
+// The number of items `Enumerate()` reads per multicall when no `PageSize` is given.
+const DefaultEnumeratePageSize = 1000
+
+/*
+ * Reads an on-chain collection exposed as a length and an indexed getter, such as `allPairsLength()` and
+ * `allPairs(i)`, or `getRoleMemberCount(role)` and `getRoleMember(role, i)`.
+ *
+ * The count and every page of items are read at the same block: the one given in `options.CallOpts`, or the
+ * latest block when enumeration starts. Any failed item call fails the whole enumeration. The count isn't trusted
+ * beyond that: pass a `Limit` when it may be huge, since items are read until it's reached.
+ *
+ *	pairs, err := multicall.Enumerate(mc, length, func(i uint64) (*multicall.MultiCallMetaData[common.Address], error) {
+ *		return multicall.Describe[common.Address](factory, factoryAbi, "allPairs", new(big.Int).SetUint64(i))
+ *	}, nil)
+ */
+func Enumerate[T any](mc *MulticallClient, count *MultiCallMetaData[uint64], item func(index uint64) (*MultiCallMetaData[T], error), options *EnumerateOptions) ([]T, error) {
+	if options == nil {
+		options = &EnumerateOptions{}
+	}
+	pinned, err := mc.pinBlockNumber(mc.callOptions(options.CallOpts))
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := DoManyValuesWithOptions(mc, pinned, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the count: %w", err)
+	}
+	end := counts[0]
+	if options.Offset >= end {
+		return []T{}, nil
+	}
+	if options.Limit > 0 && end-options.Offset > options.Limit {
+		end = options.Offset + options.Limit
+	}
+
+	pageSize := options.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultEnumeratePageSize
+	}
+
+	// don't allocate for the whole count up front, it comes from the contract.
+	items := make([]T, 0, min(end-options.Offset, pageSize))
+	for start := options.Offset; start < end; {
+		pageEnd := end
+		if end-start > pageSize {
+			pageEnd = start + pageSize
+		}
+		calls := make([]*MultiCallMetaData[T], 0, pageEnd-start)
+		for i := start; i < pageEnd; i++ {
+			call, err := item(i)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			calls = append(calls, call)
+		}
+
+		values, err := DoManyValuesWithOptions(mc, pinned, calls...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read items %d to %d: %w", start, pageEnd-1, err)
+		}
+		items = append(items, values...)
+		start = pageEnd
+	}
+	return items, nil
+}
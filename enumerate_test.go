@@ -0,0 +1,105 @@
+package multicall
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumerate(t *testing.T) {
+	chain := newFakeChain()
+	factory := fakeAddress(0xfac)
+	chain.contracts[factory] = fakeFactory(10)
+	mc := newFakeClient(t, chain)
+	factoryAbi := mustParseABI(t, factoryTestAbi)
+
+	length, err := Describe[uint64](factory, factoryAbi, "allPairsLength")
+	assert.NoError(t, err)
+	allPairs := func(i uint64) (*MultiCallMetaData[common.Address], error) {
+		return Describe[common.Address](factory, factoryAbi, "allPairs", new(big.Int).SetUint64(i))
+	}
+
+	pairs, err := Enumerate(mc, length, allPairs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, pairs, 10)
+	for i, pair := range pairs {
+		assert.Equal(t, fakeAddress(0x1000+i), pair)
+	}
+
+	// read the count, then 3 pages of at most 2 items (the block is pinned with eth_blockNumber).
+	chain.ethCalls = 0
+	page, err := Enumerate(mc, length, allPairs, &EnumerateOptions{Offset: 3, Limit: 5, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{fakeAddress(0x1003), fakeAddress(0x1004), fakeAddress(0x1005), fakeAddress(0x1006), fakeAddress(0x1007)}, page)
+	assert.Equal(t, 4, chain.ethCalls)
+
+	none, err := Enumerate(mc, length, allPairs, &EnumerateOptions{Offset: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, none)
+
+	// reading the count mines a block, but every page still reads from the block enumeration started at.
+	start := chain.blockNumber
+	blocks, err := Enumerate(mc, length, func(i uint64) (*MultiCallMetaData[uint64], error) {
+		return Describe[uint64](factory, factoryAbi, "observedBlock")
+	}, &EnumerateOptions{PageSize: 3})
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 10)
+	for _, block := range blocks {
+		assert.Equal(t, start, block)
+	}
+
+	blocks, err = Enumerate(mc, length, func(i uint64) (*MultiCallMetaData[uint64], error) {
+		return Describe[uint64](factory, factoryAbi, "observedBlock")
+	}, &EnumerateOptions{Limit: 1, CallOpts: &bind.CallOpts{BlockNumber: big.NewInt(42)}})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{42}, blocks)
+
+	// items that don't exist fail the enumeration.
+	tooMany, err := Describe[uint64](factory, factoryAbi, "observedBlock")
+	assert.NoError(t, err)
+	_, err = Enumerate(mc, tooMany, allPairs, &EnumerateOptions{Limit: 20})
+	assert.Error(t, err)
+}
+
+func TestEnumerate_HugeCount(t *testing.T) {
+	chain := newFakeChain()
+	factory := fakeAddress(0xfac)
+	factoryAbi := mustParseABI(t, factoryTestAbi)
+	chain.contracts[factory] = abiContract(factoryAbi, map[string]fakeMethod{
+		"allPairsLength": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{new(big.Int).SetUint64(math.MaxUint64)}, nil
+		},
+		"allPairs": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{common.BigToAddress(args[0].(*big.Int))}, nil
+		},
+	})
+	mc := newFakeClient(t, chain)
+	mc.MaxBatchSize = 1 << 30 // one aggregate per page
+
+	length, err := Describe[uint64](factory, factoryAbi, "allPairsLength")
+	assert.NoError(t, err)
+	allPairs := func(i uint64) (*MultiCallMetaData[common.Address], error) {
+		return Describe[common.Address](factory, factoryAbi, "allPairs", new(big.Int).SetUint64(i))
+	}
+
+	// read the count, then pages of the default size.
+	chain.ethCalls = 0
+	pairs, err := Enumerate(mc, length, allPairs, &EnumerateOptions{Limit: DefaultEnumeratePageSize + 1})
+	assert.NoError(t, err)
+	assert.Len(t, pairs, DefaultEnumeratePageSize+1)
+	assert.Equal(t, common.BigToAddress(big.NewInt(DefaultEnumeratePageSize)), pairs[DefaultEnumeratePageSize])
+	assert.Equal(t, 3, chain.ethCalls)
+
+	// the last page ends at the count, without overflowing.
+	last, err := Enumerate(mc, length, allPairs, &EnumerateOptions{Offset: math.MaxUint64 - 3, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{
+		common.BigToAddress(new(big.Int).SetUint64(math.MaxUint64 - 3)),
+		common.BigToAddress(new(big.Int).SetUint64(math.MaxUint64 - 2)),
+		common.BigToAddress(new(big.Int).SetUint64(math.MaxUint64 - 1)),
+	}, last)
+}
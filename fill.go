@@ -0,0 +1,225 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const fillTagName = "multicall"
+
+// A contract that `multicall` struct tags can refer to by name.
+type FillContract struct {
+	Address common.Address
+	// Only needed by fields that use `method=`; fields using `sig=` carry their own ABI.
+	ABI *abi.ABI
+}
+
+// The runtime values that `Fill()` substitutes into struct tags.
+type FillBindings struct {
+	// Contracts referred to by `target=<name>`. Fields without a target use the "" entry.
+	Contracts map[string]FillContract
+	// Arguments referred to by `args=<name>,<name>...`.
+	Args map[string]interface{}
+}
+
+type fillTag struct {
+	target    string
+	method    string
+	signature string
+	args      []string
+}
+
+type fillField struct {
+	index    int
+	name     string
+	optional bool
+}
+
+/*
+ * Fills the tagged fields of the struct pointed to by `dst` with a single multicall.
+ *
+ * Each field describes its call in a `multicall` tag:
+ *	Slot0    Slot0    `multicall:"target=pool,method=slot0"`
+ *	Balance  big.Int  `multicall:"target=token,method=balanceOf,args=owner"`
+ *	Allowed  *big.Int `multicall:"target=token,sig=allowance(address,address)(uint256),args=owner,spender"`
+ *
+ * `target` names an entry of `bindings.Contracts` (defaulting to ""), `method` a method of that contract's ABI
+ * and `sig` a human-readable signature used instead of the ABI. `args` lists entries of `bindings.Args`.
+ * Untagged fields, and fields tagged `multicall:"-"`, are left alone.
+ *
+ * Pointer fields are optional: if their call fails they're set to nil. Any other failed field makes Fill
+ * return an error naming it, after every other field has been filled.
+ */
+func Fill(ctx context.Context, mc *MulticallClient, dst interface{}, bindings FillBindings) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Fill needs a pointer to a struct, got %T", dst)
+	}
+	target = target.Elem()
+
+	fields := []fillField{}
+	calls := []RawMulticall{}
+	for i := 0; i < target.NumField(); i++ {
+		structField := target.Type().Field(i)
+		raw, ok := structField.Tag.Lookup(fillTagName)
+		if !ok || raw == "-" {
+			continue
+		}
+		if !structField.IsExported() {
+			return fmt.Errorf("field %s: tagged fields must be exported", structField.Name)
+		}
+		tag, err := parseFillTag(raw)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", structField.Name, err)
+		}
+
+		fieldType := structField.Type
+		optional := fieldType.Kind() == reflect.Pointer
+		if optional {
+			fieldType = fieldType.Elem()
+		}
+		call, err := describeFillCall(tag, bindings, fieldType)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", structField.Name, err)
+		}
+		fields = append(fields, fillField{index: i, name: structField.Name, optional: optional})
+		calls = append(calls, call)
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+
+	results, err := doMultiCallMany(mc, callOptionsWithContext(mc, ctx), calls...)
+	if err != nil {
+		return fmt.Errorf("multicall failed: %s", err.Error())
+	}
+
+	errs := []error{}
+	for i, field := range fields {
+		dstField := target.Field(field.index)
+		res := results[i]
+		if !res.Success {
+			if field.optional {
+				dstField.Set(reflect.Zero(dstField.Type()))
+				continue
+			}
+			errs = append(errs, fmt.Errorf("field %s: %v", field.name, res.Value))
+			continue
+		}
+		value := res.Value.(reflect.Value)
+		if field.optional {
+			dstField.Set(value)
+		} else {
+			dstField.Set(value.Elem())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func describeFillCall(tag fillTag, bindings FillBindings, fieldType reflect.Type) (RawMulticall, error) {
+	contract, ok := bindings.Contracts[tag.target]
+	if !ok {
+		return RawMulticall{}, fmt.Errorf("no contract bound to target %q", tag.target)
+	}
+
+	var method abi.Method
+	switch {
+	case tag.signature != "":
+		parsed, err := parseSignatureCached(tag.signature)
+		if err != nil {
+			return RawMulticall{}, err
+		}
+		method = parsed.method
+	case tag.method != "":
+		if contract.ABI == nil {
+			return RawMulticall{}, fmt.Errorf("target %q has no ABI to look up %q in", tag.target, tag.method)
+		}
+		if method, ok = contract.ABI.Methods[tag.method]; !ok {
+			return RawMulticall{}, fmt.Errorf("method %q not found in the ABI of target %q", tag.method, tag.target)
+		}
+	default:
+		return RawMulticall{}, errors.New("tag needs either `method` or `sig`")
+	}
+
+	args := make([]interface{}, len(tag.args))
+	for i, name := range tag.args {
+		if args[i], ok = bindings.Args[name]; !ok {
+			return RawMulticall{}, fmt.Errorf("no value bound to argument %q", name)
+		}
+	}
+	packed, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return RawMulticall{}, fmt.Errorf("error packing multicall: %s", err.Error())
+	}
+
+	outputs := method.Outputs
+	return RawMulticall{
+		Address:      contract.Address,
+		Data:         append(append([]byte{}, method.ID...), packed...),
+		FunctionName: method.Name,
+		Deserialize: func(data []byte) (any, error) {
+			value := reflect.New(fieldType)
+			if err := unpackInto(outputs, data, value.Elem()); err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+	}, nil
+}
+
+// parseFillTag parses `key=value` pairs separated by commas. Bare values continue the previous key's list, which
+// lets `args=owner,spender` name several arguments; commas inside a signature's parentheses are left alone.
+func parseFillTag(raw string) (fillTag, error) {
+	tag := fillTag{}
+	lastKey := ""
+	for _, part := range splitTopLevel(raw) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, hasKey := strings.Cut(part, "=")
+		if !hasKey {
+			if lastKey != "args" {
+				return tag, fmt.Errorf("unexpected %q in tag", part)
+			}
+			tag.args = append(tag.args, part)
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "target":
+			tag.target = value
+		case "method":
+			tag.method = value
+		case "sig":
+			tag.signature = value
+		case "args":
+			if value != "" {
+				tag.args = append(tag.args, value)
+			}
+		default:
+			return tag, fmt.Errorf("unknown key %q in tag", key)
+		}
+		lastKey = key
+	}
+	return tag, nil
+}
+
+// callOptionsWithContext returns the client's call options, bound to ctx.
+func callOptionsWithContext(mc *MulticallClient, ctx context.Context) *bind.CallOpts {
+	opts := bind.CallOpts{}
+	if mc.OverrideCallOptions != nil {
+		opts = *mc.OverrideCallOptions
+	}
+	opts.Context = ctx
+	return &opts
+}
@@ -0,0 +1,88 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFill(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x20)
+	pod := fakeAddress(0xe1)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	chain.contracts[pod] = fakeEigenPod(fakeAddress(0x0e))
+	mc := newFakeClient(t, chain)
+
+	erc20 := mustParseABI(t, erc20TestAbi)
+	eigenpodAbi, _ := EigenPodMetaData.GetAbi()
+
+	type dashboard struct {
+		Symbol    string                 `multicall:"target=token,method=symbol"`
+		Decimals  uint8                  `multicall:"target=token,sig=decimals()(uint8)"`
+		Balance   big.Int                `multicall:"target=token,method=balanceOf,args=owner"`
+		Blocked   *big.Int               `multicall:"target=token,method=balanceOf,args=blocked"`
+		PodOwner  common.Address         `multicall:"method=podOwner"`
+		Validator IEigenPodValidatorInfo `multicall:"sig=validatorPubkeyToInfo(bytes)((uint64,uint64,uint64,uint8)),args=pubkey"`
+		Ignored   string                 `multicall:"-"`
+		Untagged  int
+	}
+
+	pubkey := make([]byte, 48)
+	pubkey[0] = 7
+	bindings := FillBindings{
+		Contracts: map[string]FillContract{
+			"token": {Address: token, ABI: &erc20},
+			"":      {Address: pod, ABI: eigenpodAbi},
+		},
+		Args: map[string]interface{}{
+			"owner":   fakeAddress(0x1234),
+			"blocked": common.HexToAddress("0xdead000000000000000000000000000000000001"),
+			"pubkey":  pubkey,
+		},
+	}
+
+	view := dashboard{Ignored: "untouched", Blocked: big.NewInt(5)}
+	assert.NoError(t, Fill(context.Background(), mc, &view, bindings))
+	assert.Equal(t, 1, chain.ethCalls)
+
+	assert.Equal(t, "TKN", view.Symbol)
+	assert.Equal(t, uint8(18), view.Decimals)
+	assert.Equal(t, *fakeBalance(fakeAddress(0x1234)), view.Balance)
+	assert.Nil(t, view.Blocked) // optional, and its call failed
+	assert.Equal(t, fakeAddress(0x0e), view.PodOwner)
+	assert.Equal(t, uint64(7), view.Validator.ValidatorIndex)
+	assert.Equal(t, "untouched", view.Ignored)
+
+	// required fields that fail are reported by name.
+	type strict struct {
+		Blocked big.Int `multicall:"target=token,method=balanceOf,args=blocked"`
+	}
+	err := Fill(context.Background(), mc, &strict{}, bindings)
+	assert.ErrorContains(t, err, "Blocked")
+
+	// and so are mistakes in the tags.
+	type missingArg struct {
+		Balance big.Int `multicall:"target=token,method=balanceOf,args=nobody"`
+	}
+	assert.ErrorContains(t, Fill(context.Background(), mc, &missingArg{}, bindings), "nobody")
+	type unknownMethod struct {
+		Balance big.Int `multicall:"target=token,method=balanceOff"`
+	}
+	assert.ErrorContains(t, Fill(context.Background(), mc, &unknownMethod{}, bindings), "balanceOff")
+	assert.Error(t, Fill(context.Background(), mc, view, bindings))
+}
+
+func TestParseFillTag(t *testing.T) {
+	tag, err := parseFillTag("target=token, sig=allowance(address,address)(uint256), args=owner,spender")
+	assert.NoError(t, err)
+	assert.Equal(t, fillTag{target: "token", signature: "allowance(address,address)(uint256)", args: []string{"owner", "spender"}}, tag)
+
+	_, err = parseFillTag("method=balanceOf,owner")
+	assert.Error(t, err)
+	_, err = parseFillTag("methd=balanceOf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+/*
+ * Returns a copy of the call that's made from `from`, for view methods that depend on msg.sender ("my position")
+ * or that refuse to be called by contracts.
+ *
+ * Such calls can't go through the multicall contract, so they're made as individual eth_calls, sent together in
+ * JSON-RPC batches of at most `MaxRPCBatchLength` alongside the rest of the request. Their results come back in
+ * their original position.
+ */
+func (md *MultiCallMetaData[T]) WithFrom(from common.Address) *MultiCallMetaData[T] {
+	call := *md
+	call.From = &from
+	return &call
+}
+
+// executeMixed makes the calls with a From individually, and the others through the multicall contract.
+func executeMixed(mc *MulticallClient, overrideOpts *bind.CallOpts, calls []RawMulticall) ([]Multicall3Result, error) {
+	individual := []RawMulticall{}
+	aggregated := []RawMulticall{}
+	for _, call := range calls {
+		if call.From != nil {
+			individual = append(individual, call)
+		} else {
+			aggregated = append(aggregated, call)
+		}
+	}
+
+	opts := mc.callOptions(overrideOpts)
+	if len(aggregated) > 0 || len(individual) > mc.rpcBatchLength() {
+		// the calls span several requests, so make sure they read the same block.
+		pinned, err := mc.pinBlockNumber(opts)
+		if err != nil {
+			return nil, err
+		}
+		opts = pinned
+	}
+
+	individualResults, err := executeIndividually(mc, opts, individual)
+	if err != nil {
+		return nil, err
+	}
+	aggregatedResults := []Multicall3Result{}
+	if len(aggregated) > 0 {
+		if aggregatedResults, err = executeMulticallWith(mc, opts, aggregated, mc.aggregator()); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]Multicall3Result, 0, len(calls))
+	for _, call := range calls {
+		if call.From != nil {
+			results = append(results, individualResults[0])
+			individualResults = individualResults[1:]
+		} else {
+			results = append(results, aggregatedResults[0])
+			aggregatedResults = aggregatedResults[1:]
+		}
+	}
+	return results, nil
+}
+
+// The default `MaxRPCBatchLength`, which common providers accept.
+const defaultRPCBatchLength = 100
+
+func (mc *MulticallClient) rpcBatchLength() int {
+	if mc.MaxRPCBatchLength == 0 {
+		return defaultRPCBatchLength
+	}
+	return int(mc.MaxRPCBatchLength)
+}
+
+// executeIndividually makes each call as its own eth_call from its `From`, in JSON-RPC batches of at most
+// `MaxRPCBatchLength` requests.
+func executeIndividually(mc *MulticallClient, opts *bind.CallOpts, calls []RawMulticall) ([]Multicall3Result, error) {
+	if mc.Client == nil {
+		return nil, ErrNoClient
+	}
+	results := make([]Multicall3Result, 0, len(calls))
+	for start := 0; start < len(calls); start += mc.rpcBatchLength() {
+		end := min(start+mc.rpcBatchLength(), len(calls))
+		chunk, err := executeBatch(mc, opts, calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunk...)
+	}
+	return results, nil
+}
+
+// executeBatch makes the calls in a single JSON-RPC batch.
+func executeBatch(mc *MulticallClient, opts *bind.CallOpts, calls []RawMulticall) ([]Multicall3Result, error) {
+	block := blockArg(opts)
+	outputs := make([]hexutil.Bytes, len(calls))
+	batch := make([]rpc.BatchElem, len(calls))
+	for i, call := range calls {
+		args := map[string]interface{}{
+			"from":  *call.From,
+			"to":    call.Address,
+			"input": hexutil.Bytes(call.Data),
+		}
+		if call.Value != nil && call.Value.Sign() > 0 {
+			args["value"] = (*hexutil.Big)(call.Value)
+		}
+		batch[i] = rpc.BatchElem{Method: "eth_call", Args: []interface{}{args, block}, Result: &outputs[i]}
+	}
+	if err := mc.Client.Client().BatchCallContext(callContext(mc, opts), batch); err != nil {
+		return nil, fmt.Errorf("batched eth_call failed: %s", err.Error())
+	}
+
+	results := make([]Multicall3Result, len(calls))
+	for i, elem := range batch {
+		if elem.Error == nil {
+			results[i] = Multicall3Result{Success: true, ReturnData: outputs[i]}
+			continue
+		}
+		if !isRevert(elem.Error) {
+			return nil, fmt.Errorf("eth_call of %s failed: %s", calls[i].FunctionName, elem.Error.Error())
+		}
+		results[i] = Multicall3Result{Success: false, ReturnData: revertDataOf(elem.Error)}
+	}
+	return results, nil
+}
+
+// blockArg encodes the block selected by `opts` as an eth_call parameter.
+func blockArg(opts *bind.CallOpts) interface{} {
+	switch {
+	case opts == nil:
+		return "latest"
+	case opts.BlockHash != (common.Hash{}):
+		return rpc.BlockNumberOrHashWithHash(opts.BlockHash, false)
+	case opts.Pending:
+		return "pending"
+	case opts.BlockNumber != nil:
+		return hexutil.EncodeBig(opts.BlockNumber)
+	}
+	return "latest"
+}
@@ -0,0 +1,134 @@
+package multicall
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/assert"
+)
+
+const positionsTestAbi = `[
+	{"type":"function","name":"myPosition","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+// fakePositions reports the caller's position, derived from its address, and refuses to answer contracts.
+func fakePositions() fakeContract {
+	parsed := panicIfError(abi.JSON(strings.NewReader(positionsTestAbi)))
+	return abiContract(parsed, map[string]fakeMethod{
+		"myPosition": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			if _, isContract := ctx.chain.contracts[ctx.from]; isContract {
+				return nil, revertWithReason("EOA only")
+			}
+			return []interface{}{new(big.Int).Add(fakeBalance(ctx.from), new(big.Int).SetUint64(ctx.block))}, nil
+		},
+	})
+}
+
+func TestWithFrom(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0xa0)
+	positions := fakeAddress(0xb0)
+	chain.contracts[token] = fakeERC20("A", 18)
+	chain.contracts[positions] = fakePositions()
+	mc := newFakeClient(t, chain)
+	erc20 := mustParseABI(t, erc20TestAbi)
+	positionsAbi := mustParseABI(t, positionsTestAbi)
+
+	myPosition, err := Describe[big.Int](positions, positionsAbi, "myPosition")
+	assert.NoError(t, err)
+	balance, err := Describe[big.Int](token, erc20, "balanceOf", fakeAddress(1))
+	assert.NoError(t, err)
+	alice, bob := fakeAddress(0x0a11), fakeAddress(0x0b0b)
+
+	chain.ethCalls = 0
+	results, err := DoManyResults(mc, myPosition.WithFrom(alice), balance, myPosition, myPosition.WithFrom(bob), myPosition.WithFrom(token))
+	assert.NoError(t, err)
+	// one aggregate3, plus the three individual calls.
+	assert.Equal(t, 4, chain.ethCalls)
+	assert.Equal(t, "2677", results[0].Value.String()) // 0x0a11 + block 100
+	assert.Equal(t, *fakeBalance(fakeAddress(1)), results[1].Value)
+	assert.ErrorContains(t, results[2].Err(), "EOA only")
+	assert.Equal(t, "2927", results[3].Value.String())
+	assert.ErrorContains(t, results[4].Err(), "EOA only")
+	assert.Nil(t, myPosition.From)
+
+	old, err := DoManyValuesWithOptions(mc, &bind.CallOpts{BlockNumber: big.NewInt(50)}, myPosition.WithFrom(alice))
+	assert.NoError(t, err)
+	assert.Equal(t, "2627", old[0].String())
+
+	mapped := Map(myPosition.WithFrom(alice), func(position *big.Int) (string, error) {
+		return position.String(), nil
+	})
+	text, err := DoManyValues(mc, mapped)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2677"}, text)
+
+	_, err = Zip(mc, myPosition.WithFrom(alice), balance)
+	assert.Error(t, err)
+	_, err = DoManyAttested(mc, myPosition.WithFrom(alice))
+	assert.Error(t, err)
+}
+
+func TestWithFrom_Batches(t *testing.T) {
+	chain := newFakeChain()
+	positions := fakeAddress(0xb0)
+	chain.contracts[positions] = fakePositions()
+	chain.rpcBatchLimit = 2
+	mc := newFakeClient(t, chain)
+	myPosition, err := Describe[big.Int](positions, mustParseABI(t, positionsTestAbi), "myPosition")
+	assert.NoError(t, err)
+
+	calls := make([]*MultiCallMetaData[big.Int], 5)
+	for i := range calls {
+		calls[i] = myPosition.WithFrom(fakeAddress(0x0a00 + i))
+	}
+	// the node only takes 2 requests per batch.
+	mc.MaxRPCBatchLength = 3
+	_, err = DoManyValues(mc, calls...)
+	assert.Error(t, err)
+
+	mc.MaxRPCBatchLength = 2
+	values, err := DoManyValues(mc, calls...)
+	assert.NoError(t, err)
+	for i, value := range values {
+		assert.Equal(t, new(big.Int).Add(fakeBalance(fakeAddress(0x0a00+i)), big.NewInt(100)).String(), value.String())
+	}
+
+	_, err = executeIndividually(&MulticallClient{}, nil, []RawMulticall{calls[0].Raw()})
+	assert.ErrorIs(t, err, ErrNoClient)
+}
+
+func TestWithFrom_Serialized(t *testing.T) {
+	chain := newFakeChain()
+	positions := fakeAddress(0xb0)
+	chain.contracts[positions] = fakePositions()
+	mc := newFakeClient(t, chain)
+	myPosition, err := Describe[big.Int](positions, mustParseABI(t, positionsTestAbi), "myPosition")
+	assert.NoError(t, err)
+	alice := fakeAddress(0x0a11)
+	call := myPosition.WithFrom(alice)
+
+	descriptor, err := NewCallDescriptor(call, nil)
+	assert.NoError(t, err)
+	encoded, err := descriptor.Marshal()
+	assert.NoError(t, err)
+	var received CallDescriptor
+	assert.NoError(t, received.Unmarshal(encoded))
+	compiled, err := CompileAs[big.Int](&received)
+	assert.NoError(t, err)
+
+	encoded, err = json.Marshal(call)
+	assert.NoError(t, err)
+	var decoded MultiCallMetaData[big.Int]
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+
+	// both are still made from alice, rather than by the multicall contract.
+	values, err := DoManyValues(mc, compiled, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, "2677", values[0].String())
+	assert.Equal(t, "2677", values[1].String())
+}
@@ -0,0 +1,54 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// A call tagged with the key its result should be stored under.
+type KeyedCall[K comparable, A any] struct {
+	Key  K
+	Call *MultiCallMetaData[A]
+}
+
+// Executes one call per key and returns the results under the same keys.
+func DoManyKeyed[K comparable, A any](mc *MulticallClient, calls map[K]*MultiCallMetaData[A]) (map[K]Result[A], error) {
+	return DoManyKeyedWithOptions(mc, nil, calls)
+}
+
+func DoManyKeyedWithOptions[K comparable, A any](mc *MulticallClient, options *bind.CallOpts, calls map[K]*MultiCallMetaData[A]) (map[K]Result[A], error) {
+	pairs := make([]KeyedCall[K, A], 0, len(calls))
+	for key, call := range calls {
+		pairs = append(pairs, KeyedCall[K, A]{Key: key, Call: call})
+	}
+	return DoManyKeyedPairsWithOptions(mc, options, pairs)
+}
+
+// Like `DoManyKeyed()`, but the calls are executed in the order given. Keys must be unique.
+func DoManyKeyedPairs[K comparable, A any](mc *MulticallClient, calls []KeyedCall[K, A]) (map[K]Result[A], error) {
+	return DoManyKeyedPairsWithOptions(mc, nil, calls)
+}
+
+func DoManyKeyedPairsWithOptions[K comparable, A any](mc *MulticallClient, options *bind.CallOpts, calls []KeyedCall[K, A]) (map[K]Result[A], error) {
+	seen := make(map[K]struct{}, len(calls))
+	for _, call := range calls {
+		if _, ok := seen[call.Key]; ok {
+			return nil, fmt.Errorf("duplicate key %v", call.Key)
+		}
+		seen[call.Key] = struct{}{}
+	}
+
+	results, err := DoManyResultsWithOptions(mc, options, mapCollection(calls, func(call KeyedCall[K, A], _ uint64) *MultiCallMetaData[A] {
+		return call.Call
+	})...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[K]Result[A], len(calls))
+	for i, call := range calls {
+		out[call.Key] = results[i]
+	}
+	return out, nil
+}
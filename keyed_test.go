@@ -0,0 +1,52 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoManyKeyed(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x20)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+	mc.MaxBatchSize = 36 * 3 // force a few chunks
+
+	blocked := common.HexToAddress("0xdead000000000000000000000000000000000001")
+	holders := []common.Address{fakeAddress(1), fakeAddress(2), fakeAddress(3), fakeAddress(4), fakeAddress(5), blocked}
+
+	calls := map[common.Address]*MultiCallMetaData[big.Int]{}
+	for _, holder := range holders {
+		calls[holder] = panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", holder))
+	}
+
+	results, err := DoManyKeyed(mc, calls)
+	assert.NoError(t, err)
+	assert.Len(t, results, len(holders))
+	for _, holder := range holders[:5] {
+		assert.Equal(t, *fakeBalance(holder), results[holder].Value, holder.Hex())
+	}
+	assert.False(t, results[blocked].Ok())
+}
+
+func TestDoManyKeyedPairs(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x20)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+
+	pairs := []KeyedCall[string, big.Int]{
+		{Key: "alice", Call: panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", fakeAddress(10)))},
+		{Key: "bob", Call: panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", fakeAddress(11)))},
+	}
+	results, err := DoManyKeyedPairs(mc, pairs)
+	assert.NoError(t, err)
+	assert.Equal(t, *fakeBalance(fakeAddress(10)), results["alice"].Value)
+	assert.Equal(t, *fakeBalance(fakeAddress(11)), results["bob"].Value)
+
+	_, err = DoManyKeyedPairs(mc, append(pairs, pairs[0]))
+	assert.Error(t, err)
+}
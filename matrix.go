@@ -0,0 +1,75 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// One method called on several targets, with several argument lists.
+type CallMatrix[T any] struct {
+	Targets []common.Address
+	Args    [][]interface{}
+	// Calls[i][j] calls Targets[i] with Args[j].
+	Calls [][]*MultiCallMetaData[T]
+}
+
+// Describes the same call on every target, e.g. `decimals()` for a list of tokens.
+func DescribeForTargets[T any](targets []common.Address, contractAbi abi.ABI, method string, params ...interface{}) (*CallMatrix[T], error) {
+	return DescribeProduct[T](targets, contractAbi, method, [][]interface{}{params})
+}
+
+// Describes `method` for every pair of target and argument list, e.g. `balanceOf(owner)` for each token × wallet.
+func DescribeProduct[T any](targets []common.Address, contractAbi abi.ABI, method string, argsList [][]interface{}) (*CallMatrix[T], error) {
+	calls := make([][]*MultiCallMetaData[T], len(targets))
+	for i, target := range targets {
+		calls[i] = make([]*MultiCallMetaData[T], len(argsList))
+		for j, args := range argsList {
+			call, err := Describe[T](target, contractAbi, method, args...)
+			if err != nil {
+				return nil, fmt.Errorf("target %d, args %d: %w", i, j, err)
+			}
+			calls[i][j] = call
+		}
+	}
+	return &CallMatrix[T]{Targets: targets, Args: argsList, Calls: calls}, nil
+}
+
+// Returns the calls in row order, i.e. all argument lists for the first target, then for the second...
+func (m *CallMatrix[T]) Flatten() []*MultiCallMetaData[T] {
+	flat := []*MultiCallMetaData[T]{}
+	for _, row := range m.Calls {
+		flat = append(flat, row...)
+	}
+	return flat
+}
+
+// Executes the matrix, allowing failures. results[i][j] is the result of `Calls[i][j]`; failed cells hold why they
+// failed in `Error` (a `*RevertError` when the call reverted).
+func DoMatrix[T any](mc *MulticallClient, m *CallMatrix[T]) ([][]TypedMulticall3Result[*T], error) {
+	return DoMatrixWithOptions(mc, nil, m)
+}
+
+func DoMatrixWithOptions[T any](mc *MulticallClient, options *bind.CallOpts, m *CallMatrix[T]) ([][]TypedMulticall3Result[*T], error) {
+	res, err := DoManyResultsWithOptions(mc, options, m.Flatten()...)
+	if err != nil {
+		return nil, err
+	}
+	flat := mapCollection(res, func(r Result[T], _ uint64) TypedMulticall3Result[*T] {
+		if !r.Ok() {
+			return TypedMulticall3Result[*T]{Success: false, Error: r.Err()}
+		}
+		value := r.Value
+		return TypedMulticall3Result[*T]{Success: true, Value: &value}
+	})
+
+	results := make([][]TypedMulticall3Result[*T], len(m.Calls))
+	offset := 0
+	for i, row := range m.Calls {
+		results[i] = flat[offset : offset+len(row)]
+		offset += len(row)
+	}
+	return results, nil
+}
@@ -0,0 +1,52 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeProduct(t *testing.T) {
+	chain := newFakeChain()
+	tokens := []common.Address{fakeAddress(0xa0), fakeAddress(0xb0), fakeAddress(0xc0)}
+	chain.contracts[tokens[0]] = fakeERC20("A", 18)
+	chain.contracts[tokens[1]] = fakeERC20("B", 6)
+	chain.contracts[tokens[2]] = fakeERC20("C", 8)
+	mc := newFakeClient(t, chain)
+	erc20 := mustParseABI(t, erc20TestAbi)
+
+	blocked := common.HexToAddress("0xdead000000000000000000000000000000000001")
+	wallets := []common.Address{fakeAddress(0x0101), blocked}
+	matrix, err := DescribeProduct[big.Int](tokens, erc20, "balanceOf", [][]interface{}{{wallets[0]}, {wallets[1]}})
+	assert.NoError(t, err)
+	assert.Len(t, matrix.Flatten(), 6)
+
+	results, err := DoMatrix(mc, matrix)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	for i := range tokens {
+		assert.Len(t, results[i], 2)
+		assert.True(t, results[i][0].Success)
+		assert.Equal(t, fakeBalance(wallets[0]), results[i][0].Value)
+		// balanceOf reverts for holders starting with 0xdead.
+		assert.False(t, results[i][1].Success)
+		assert.Nil(t, results[i][1].Value)
+		var revert *RevertError
+		assert.ErrorAs(t, results[i][1].Error, &revert)
+		assert.Equal(t, "blocked holder", revert.Reason)
+		assert.NoError(t, results[i][0].Error)
+	}
+
+	decimals, err := DescribeForTargets[uint8](tokens, erc20, "decimals")
+	assert.NoError(t, err)
+	decimalResults, err := DoMatrix(mc, decimals)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(18), *decimalResults[0][0].Value)
+	assert.Equal(t, uint8(6), *decimalResults[1][0].Value)
+	assert.Equal(t, uint8(8), *decimalResults[2][0].Value)
+
+	_, err = DescribeProduct[big.Int](tokens, erc20, "balanceOf", [][]interface{}{{"not an address"}})
+	assert.Error(t, err)
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -17,11 +19,32 @@ import (
 //go:embed multicallAbi.json
 var multicallAbi string
 
+// also taken from: https://www.multicall3.com/ -- it's deployed at the same addr on most chains
+const defaultMulticallAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+var (
+	// The call reverted on-chain.
+	ErrCallFailed = errors.New("call failed")
+	// The call succeeded, but returned nothing to deserialize (e.g. the target has no code).
+	ErrNoData = errors.New("no data returned")
+	// The request needs the node's client (e.g. for raw calls or transactions), which only `NewMulticallClient` sets.
+	ErrNoClient = errors.New("the client wasn't created with NewMulticallClient")
+)
+
 type MultiCallMetaData[T interface{}] struct {
 	Address      common.Address
 	Data         []byte
 	FunctionName string
 	Deserialize  func([]byte) (*T, error)
+	// The ABI of the method being called, when known. Used to encode the call and its results as JSON.
+	Method *abi.Method
+	// Decodes the raw result, whether or not the call reverted, instead of `Deserialize`. Optional; used by calls
+	// that return their results in revert data (see `DescribeRevert()`).
+	DecodeResult func(Multicall3Result) (*T, error)
+	// The msg.value sent with the call, for payable methods. Optional; see `WithValue()`.
+	Value *big.Int
+	// The msg.sender the call is made from, instead of the multicall contract. Optional; see `WithFrom()`.
+	From *common.Address
 }
 
 type Multicall3Result struct {
@@ -41,14 +64,28 @@ type DeserializedMulticall3Result struct {
 }
 
 func (md *MultiCallMetaData[T]) Raw() RawMulticall {
+	raw := md.rawCall()
+	raw.Deserialize = func(data []byte) (any, error) {
+		res, err := md.Deserialize(data)
+		return any(res), err
+	}
+	if md.DecodeResult != nil {
+		raw.DecodeResult = func(res Multicall3Result) (any, error) {
+			val, err := md.DecodeResult(res)
+			return any(val), err
+		}
+	}
+	return raw
+}
+
+// rawCall describes the call for execution only, without a deserializer.
+func (md *MultiCallMetaData[T]) rawCall() RawMulticall {
 	return RawMulticall{
 		Address:      md.Address,
 		Data:         md.Data,
 		FunctionName: md.FunctionName,
-		Deserialize: func(data []byte) (any, error) {
-			res, err := md.Deserialize(data)
-			return any(res), err
-		},
+		Value:        md.Value,
+		From:         md.From,
 	}
 }
 
@@ -57,6 +94,12 @@ type RawMulticall struct {
 	Data         []byte
 	FunctionName string
 	Deserialize  func([]byte) (any, error)
+	// See `MultiCallMetaData.DecodeResult`.
+	DecodeResult func(Multicall3Result) (any, error)
+	// See `MultiCallMetaData.Value`.
+	Value *big.Int
+	// See `MultiCallMetaData.From`.
+	From *common.Address
 }
 
 type MulticallClient struct {
@@ -66,18 +109,30 @@ type MulticallClient struct {
 	Context             context.Context
 	MaxBatchSize        uint64
 	OverrideCallOptions *bind.CallOpts
+	// The node calls are made through, for requests that go beyond a plain eth_call (e.g. state overrides).
+	Client *ethclient.Client
+	// The interface of the contract at `Address`. See `Dialect`.
+	Dialect Dialect
+	// The most requests sent in one JSON-RPC batch, e.g. for calls with a `From`. 0 uses the default.
+	MaxRPCBatchLength uint64
 }
 
 type ParamMulticall3Call3 struct {
 	Target       common.Address
 	AllowFailure bool
-	CallData     []byte
+	// Only sent when the batch goes through aggregate3Value.
+	Value    *big.Int
+	CallData []byte
 }
 
 type TMulticallClientOptions struct {
 	OverrideContractAddress *common.Address
 	MaxBatchSizeBytes       uint64
 	OverrideCallOptions     *bind.CallOpts
+	// The interface of the deployed multicall contract. Defaults to Multicall3.
+	Dialect Dialect
+	// The most requests sent in one JSON-RPC batch, as providers cap it. Defaults to 100.
+	MaxRPCBatchLength uint64
 }
 
 func panicIfError[T any](val T, err error) T {
@@ -102,10 +157,16 @@ func NewMulticallClient(ctx context.Context, eth *ethclient.Client, options *TMu
 	// taken from: https://www.multicall3.com/
 	parsed := panicIfError(abi.JSON(strings.NewReader(multicallAbi)))
 
+	dialect := func() Dialect {
+		if options == nil {
+			return DialectMulticall3
+		}
+		return options.Dialect
+	}()
+
 	contractAddress := func() common.Address {
 		if options == nil || options.OverrideContractAddress == nil {
-			// also taken from: https://www.multicall3.com/ -- it's deployed at the same addr on most chains
-			return common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+			return dialect.defaultAddress()
 		}
 		return *options.OverrideContractAddress
 	}()
@@ -118,6 +179,11 @@ func NewMulticallClient(ctx context.Context, eth *ethclient.Client, options *TMu
 		}
 	}()
 
+	maxRPCBatchLength := uint64(0)
+	if options != nil {
+		maxRPCBatchLength = options.MaxRPCBatchLength
+	}
+
 	callOptions := func() *bind.CallOpts {
 		if options != nil {
 			return options.OverrideCallOptions
@@ -125,7 +191,7 @@ func NewMulticallClient(ctx context.Context, eth *ethclient.Client, options *TMu
 		return nil
 	}()
 
-	return &MulticallClient{Address: contractAddress, OverrideCallOptions: callOptions, MaxBatchSize: maxBatchSize, Context: ctx, ABI: &parsed, Contract: bind.NewBoundContract(contractAddress, parsed, eth, eth, eth)}, nil
+	return &MulticallClient{Address: contractAddress, OverrideCallOptions: callOptions, MaxBatchSize: maxBatchSize, Context: ctx, ABI: &parsed, Contract: bind.NewBoundContract(contractAddress, parsed, eth, eth, eth), Client: eth, Dialect: dialect, MaxRPCBatchLength: maxRPCBatchLength}, nil
 }
 
 func DescribeWithDeserialize[T any](contractAddress common.Address, abi abi.ABI, deserialize func([]byte) (*T, error), method string, params ...interface{}) (*MultiCallMetaData[T], error) {
@@ -138,6 +204,7 @@ func DescribeWithDeserialize[T any](contractAddress common.Address, abi abi.ABI,
 		Data:         callData,
 		FunctionName: method,
 		Deserialize:  deserialize,
+		Method:       lookupMethod(abi, method),
 	}, nil
 }
 
@@ -149,19 +216,33 @@ func Describe[T any](contractAddress common.Address, contractAbi abi.ABI, method
 	return DescribeWithDeserialize(
 		contractAddress,
 		contractAbi,
-		func(b []byte) (*T, error) {
-			res, err := contractAbi.Unpack(method, b)
-			if err != nil {
-				return nil, err
-			}
-			output, _ := abi.ConvertType(res[0], new(T)).(*T)
-			return output, nil
-		},
+		describeDeserializer[T](contractAbi, method),
 		method,
 		params...,
 	)
 }
 
+// describeDeserializer decodes the outputs of `method` into T, the way `Describe()` does.
+func describeDeserializer[T any](contractAbi abi.ABI, method string) func([]byte) (*T, error) {
+	return func(b []byte) (*T, error) {
+		m, ok := contractAbi.Methods[method]
+		if !ok {
+			return nil, fmt.Errorf("method '%s' not found", method)
+		}
+		if len(m.Outputs) > 1 && reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.Struct {
+			return unpackOutputs[T](m.Outputs, b)
+		}
+		res, err := m.Outputs.Unpack(b)
+		if err != nil {
+			return nil, err
+		}
+		if len(res) == 0 {
+			return nil, errors.New("method has no outputs")
+		}
+		return convertValue[T](res[0])
+	}
+}
+
 func Do[A any, B any](mc *MulticallClient, a *MultiCallMetaData[A], b *MultiCallMetaData[B]) (*A, *B, error) {
 	res, err := doMultiCallMany(mc, nil, a.Raw(), b.Raw())
 	if err != nil {
@@ -249,6 +330,82 @@ func (mc *MulticallClient) GetBlockNumber() *MultiCallMetaData[big.Int] {
 	return call
 }
 
+// The hash of the given block, which is only available for the 256 most recent blocks (zero otherwise).
+func (mc *MulticallClient) GetBlockHash(blockNumber uint64) *MultiCallMetaData[common.Hash] {
+	call, _ := Describe[common.Hash](
+		mc.Address,
+		*mc.ABI,
+		"getBlockHash",
+		new(big.Int).SetUint64(blockNumber),
+	)
+	return call
+}
+
+// The hash of the block before the one the call executes in.
+func (mc *MulticallClient) GetLastBlockHash() *MultiCallMetaData[common.Hash] {
+	call, _ := Describe[common.Hash](
+		mc.Address,
+		*mc.ABI,
+		"getLastBlockHash",
+	)
+	return call
+}
+
+func (mc *MulticallClient) GetBasefee() *MultiCallMetaData[big.Int] {
+	call, _ := Describe[big.Int](
+		mc.Address,
+		*mc.ABI,
+		"getBasefee",
+	)
+	return call
+}
+
+func (mc *MulticallClient) GetChainId() *MultiCallMetaData[big.Int] {
+	call, _ := Describe[big.Int](
+		mc.Address,
+		*mc.ABI,
+		"getChainId",
+	)
+	return call
+}
+
+func (mc *MulticallClient) GetCurrentBlockTimestamp() *MultiCallMetaData[time.Time] {
+	call, _ := Describe[time.Time](
+		mc.Address,
+		*mc.ABI,
+		"getCurrentBlockTimestamp",
+	)
+	return call
+}
+
+func (mc *MulticallClient) GetCurrentBlockCoinbase() *MultiCallMetaData[common.Address] {
+	call, _ := Describe[common.Address](
+		mc.Address,
+		*mc.ABI,
+		"getCurrentBlockCoinbase",
+	)
+	return call
+}
+
+func (mc *MulticallClient) GetCurrentBlockGasLimit() *MultiCallMetaData[uint64] {
+	call, _ := Describe[uint64](
+		mc.Address,
+		*mc.ABI,
+		"getCurrentBlockGasLimit",
+	)
+	return call
+}
+
+// Since the merge this is `block.prevrandao`, not a difficulty.
+func (mc *MulticallClient) GetCurrentBlockDifficulty() *MultiCallMetaData[big.Int] {
+	call, _ := Describe[big.Int](
+		mc.Address,
+		*mc.ABI,
+		"getCurrentBlockDifficulty",
+	)
+	return call
+}
+
 // //////////////////////
 func DoManyAllowFailures[A any](mc *MulticallClient, requests ...*MultiCallMetaData[A]) (*[]TypedMulticall3Result[*A], error) {
 	return DoManyAllowFailuresWithOptions(mc, nil, requests...)
@@ -280,52 +437,98 @@ func DoManyAllowFailuresWithOptions[A any](mc *MulticallClient, overrideOpts *bi
 	return &unwoundResults, nil
 }
 
-func doMultiCallMany(mc *MulticallClient, overrideOpts *bind.CallOpts, calls ...RawMulticall) ([]DeserializedMulticall3Result, error) {
+/*
+ * executeMulticall runs the calls through aggregate3, chunking them as needed, and returns one raw result per call.
+ * Calls with a `From` are made with individual eth_calls instead (see `executeIndividually()`).
+ */
+func executeMulticall(mc *MulticallClient, overrideOpts *bind.CallOpts, calls []RawMulticall) ([]Multicall3Result, error) {
+	for _, call := range calls {
+		if call.From != nil {
+			return executeMixed(mc, overrideOpts, calls)
+		}
+	}
+	return executeMulticallWith(mc, overrideOpts, calls, mc.aggregator())
+}
+
+func (mc *MulticallClient) callOptions(overrideOpts *bind.CallOpts) *bind.CallOpts {
+	if overrideOpts != nil {
+		return overrideOpts
+	}
+	if mc.OverrideCallOptions != nil {
+		return mc.OverrideCallOptions
+	}
+	return nil
+}
+
+// An aggregator executes one chunk of calls, returning one raw result per call.
+type aggregator func(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error)
+
+// executeMulticallWith is `executeMulticall()`, with each chunk executed by `aggregate`.
+func executeMulticallWith(mc *MulticallClient, overrideOpts *bind.CallOpts, calls []RawMulticall, aggregate aggregator) ([]Multicall3Result, error) {
 	typedCalls := make([]ParamMulticall3Call3, len(calls))
 	for i, call := range calls {
+		if call.From != nil {
+			return nil, fmt.Errorf("call %d (%s) has a From, which is only supported by plain batches", i, call.FunctionName)
+		}
 		typedCalls[i] = ParamMulticall3Call3{
 			Target:       call.Address,
 			AllowFailure: true,
+			Value:        call.Value,
 			CallData:     call.Data,
 		}
 	}
 
 	// see if we need to chunk them now
 	chunkedCalls := chunkCalls(typedCalls, int(mc.MaxBatchSize))
-	var results = make([]interface{}, len(calls))
-	var totalResults = 0
-
-	callOptions := func() *bind.CallOpts {
-		if overrideOpts != nil {
-			return overrideOpts
-		}
-		if mc.OverrideCallOptions != nil {
-			return mc.OverrideCallOptions
-		}
-		return nil
-	}()
+	results := make([]Multicall3Result, 0, len(calls))
 
-	chunkNumber := 1
+	callOptions := mc.callOptions(overrideOpts)
 	for _, multicalls := range chunkedCalls {
-		var res []interface{}
-		chunkNumber++
-		err := mc.Contract.Call(callOptions, &res, "aggregate3", multicalls)
+		multicallResults, err := aggregate(callOptions, multicalls)
 		if err != nil {
-			return nil, fmt.Errorf("aggregate3 failed: %s", err)
+			return nil, err
 		}
+		results = append(results, multicallResults...)
+	}
 
-		multicallResults := *abi.ConvertType(res[0], new([]Multicall3Result)).(*[]Multicall3Result)
-		for i := 0; i < len(multicallResults); i++ {
-			results[totalResults+i] = multicallResults[i]
-		}
-		totalResults += len(multicallResults)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(calls), len(results))
+	}
+	return results, nil
+}
+
+func (mc *MulticallClient) aggregate3(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+	if totalValue(calls).Sign() > 0 {
+		return mc.aggregate3Value(opts, calls)
+	}
+	var res []interface{}
+	err := mc.Contract.Call(opts, &res, "aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate3 failed: %w", err)
+	}
+	return *abi.ConvertType(res[0], new([]Multicall3Result)).(*[]Multicall3Result), nil
+}
+
+func doMultiCallMany(mc *MulticallClient, overrideOpts *bind.CallOpts, calls ...RawMulticall) ([]DeserializedMulticall3Result, error) {
+	results, err := executeMulticall(mc, overrideOpts, calls)
+	if err != nil {
+		return nil, err
 	}
 
 	outputs := make([]DeserializedMulticall3Result, len(calls))
 	for i, call := range calls {
-		res := results[i].(Multicall3Result)
+		res := results[i]
+		if call.DecodeResult != nil {
+			val, err := call.DecodeResult(res)
+			if err != nil {
+				outputs[i] = DeserializedMulticall3Result{Value: err, Success: false}
+			} else {
+				outputs[i] = DeserializedMulticall3Result{Value: val, Success: true}
+			}
+			continue
+		}
 		if res.Success {
-			if res.ReturnData != nil {
+			if len(res.ReturnData) > 0 {
 				val, err := call.Deserialize(res.ReturnData)
 				if err != nil {
 					outputs[i] = DeserializedMulticall3Result{
@@ -340,14 +543,14 @@ func doMultiCallMany(mc *MulticallClient, overrideOpts *bind.CallOpts, calls ...
 				}
 			} else {
 				outputs[i] = DeserializedMulticall3Result{
-					Value:   errors.New("no data returned"),
+					Value:   ErrNoData,
 					Success: false,
 				}
 			}
 		} else {
 			outputs[i] = DeserializedMulticall3Result{
 				Success: false,
-				Value:   errors.New("call failed"),
+				Value:   DecodeRevert(res.ReturnData),
 			}
 		}
 	}
@@ -0,0 +1,169 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The Safe v1.3.0 MultiSend contracts, deployed at the same address on every chain.
+const (
+	MultiSendAddress         = "0xA238CBeb142c10Ef7Ad8442C6D1f9E89e07e7761"
+	MultiSendCallOnlyAddress = "0x40A2aCCbd92BCA938b02010E17A5b8929b49130D"
+)
+
+// How a MultiSend transaction is executed by the Safe.
+type MultiSendOperation uint8
+
+const (
+	MultiSendCall         MultiSendOperation = 0
+	MultiSendDelegateCall MultiSendOperation = 1
+)
+
+type MultiSendTransaction struct {
+	Operation MultiSendOperation
+	// The target, value and calldata of the transaction.
+	Call RawMulticall
+}
+
+/*
+ * Builds the payload of a Safe transaction that executes several calls, from the same call descriptions used for
+ * reads. Unlike aggregate3, MultiSend is delegatecalled by the Safe, so the Safe is the msg.sender of every call.
+ *
+ * The Safe transaction goes `To()` the MultiSend contract, with operation DELEGATECALL and `Calldata()` as data.
+ */
+type MultiSend struct {
+	Transactions []MultiSendTransaction
+}
+
+func NewMultiSend(calls ...RawMulticall) *MultiSend {
+	return (&MultiSend{}).Add(calls...)
+}
+
+// Adds calls made by the Safe. The Safe is the sender of every call, so calls with a `From` make `Calldata()` and
+// `Simulate()` fail.
+func (ms *MultiSend) Add(calls ...RawMulticall) *MultiSend {
+	for _, call := range calls {
+		ms.Transactions = append(ms.Transactions, MultiSendTransaction{Operation: MultiSendCall, Call: call})
+	}
+	return ms
+}
+
+// Adds a call delegatecalled by the Safe, which requires the full MultiSend contract.
+func (ms *MultiSend) AddDelegateCall(call RawMulticall) *MultiSend {
+	ms.Transactions = append(ms.Transactions, MultiSendTransaction{Operation: MultiSendDelegateCall, Call: call})
+	return ms
+}
+
+// MultiSendCallOnly, unless a transaction is a delegatecall.
+func (ms *MultiSend) To() common.Address {
+	for _, tx := range ms.Transactions {
+		if tx.Operation == MultiSendDelegateCall {
+			return common.HexToAddress(MultiSendAddress)
+		}
+	}
+	return common.HexToAddress(MultiSendCallOnlyAddress)
+}
+
+// Returns the packed transactions: for each, the operation (1 byte), target (20), value (32), data length (32) and data.
+func (ms *MultiSend) Encode() []byte {
+	encoded := []byte{}
+	for _, tx := range ms.Transactions {
+		value := tx.Call.Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		encoded = append(encoded, byte(tx.Operation))
+		encoded = append(encoded, tx.Call.Address.Bytes()...)
+		encoded = append(encoded, common.LeftPadBytes(value.Bytes(), 32)...)
+		encoded = append(encoded, common.LeftPadBytes(big.NewInt(int64(len(tx.Call.Data))).Bytes(), 32)...)
+		encoded = append(encoded, tx.Call.Data...)
+	}
+	return encoded
+}
+
+// Returns the calldata of `multiSend(bytes transactions)`, the data of the Safe transaction.
+func (ms *MultiSend) Calldata() ([]byte, error) {
+	if err := ms.checkSenders(); err != nil {
+		return nil, err
+	}
+	bytesType, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	args, err := abi.Arguments{{Type: bytesType}}.Pack(ms.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("error packing multisend: %s", err.Error())
+	}
+	return append(crypto.Keccak256([]byte("multiSend(bytes)"))[:4], args...), nil
+}
+
+// checkSenders rejects transactions whose calls expect to be sent by someone other than the Safe.
+func (ms *MultiSend) checkSenders() error {
+	for i, tx := range ms.Transactions {
+		if tx.Call.From != nil {
+			return fmt.Errorf("transaction %d (%s) has a From, but every call is made by the Safe", i, tx.Call.FunctionName)
+		}
+	}
+	return nil
+}
+
+// The total value the Safe sends along with the transactions.
+func (ms *MultiSend) Value() *big.Int {
+	total := new(big.Int)
+	for _, tx := range ms.Transactions {
+		if tx.Call.Value != nil {
+			total.Add(total, tx.Call.Value)
+		}
+	}
+	return total
+}
+
+/*
+ * Checks the transactions before the proposal is signed, by running them with an eth_call from the Safe: Multicall3's
+ * code is overridden onto the Safe's address, and the calls are made through aggregate3 there, in a single eth_call
+ * so that each call sees the effects of the previous ones.
+ *
+ * Returns one result per transaction, decoded with its call's deserializer; failures hold a `*RevertError`. Note
+ * that the real MultiSend is atomic, so a single failure reverts the whole Safe transaction. Delegatecalls can't
+ * be simulated this way.
+ *
+ * The Safe's own code is replaced by Multicall3's for the simulation, so anything that calls back into the Safe
+ * (signature checks like `isValidSignature`, token receive hooks, modules, guards) runs against Multicall3 instead
+ * and won't behave as it will on-chain.
+ */
+func (ms *MultiSend) Simulate(mc *MulticallClient, safe common.Address, opts *bind.CallOpts) ([]DeserializedMulticall3Result, error) {
+	if len(ms.Transactions) == 0 {
+		return nil, errors.New("no transactions to simulate")
+	}
+	if err := ms.checkSenders(); err != nil {
+		return nil, err
+	}
+	params := make([]ParamMulticall3Call3, len(ms.Transactions))
+	for i, tx := range ms.Transactions {
+		if tx.Operation != MultiSendCall {
+			return nil, fmt.Errorf("transaction %d is a delegatecall, which can't be simulated", i)
+		}
+		params[i] = ParamMulticall3Call3{Target: tx.Call.Address, AllowFailure: true, Value: tx.Call.Value, CallData: tx.Call.Data}
+	}
+	if opts == nil {
+		opts = mc.OverrideCallOptions
+	}
+
+	res, err := mc.aggregate3WithOverrides(&safe, nil)(opts, params)
+	if err != nil {
+		return nil, fmt.Errorf("multisend simulation failed: %s", err.Error())
+	}
+	if len(res) != len(params) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(params), len(res))
+	}
+	calls := mapCollection(ms.Transactions, func(tx MultiSendTransaction, _ uint64) RawMulticall {
+		return tx.Call
+	})
+	return simulatedResults(calls, res), nil
+}
@@ -0,0 +1,78 @@
+package multicall
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiSendEncode(t *testing.T) {
+	token := fakeAddress(0xa0)
+	erc20 := mustParseABI(t, erc20TestAbi)
+	transfer, err := Describe[bool](token, erc20, "transfer", fakeAddress(1), big.NewInt(100))
+	assert.NoError(t, err)
+	payment := RawMulticall{Address: fakeAddress(2), Value: big.NewInt(5)}
+
+	ms := NewMultiSend(transfer.Raw(), payment)
+	assert.Equal(t, common.HexToAddress(MultiSendCallOnlyAddress), ms.To())
+	assert.Equal(t, "5", ms.Value().String())
+
+	expected := []byte{0}
+	expected = append(expected, token.Bytes()...)
+	expected = append(expected, make([]byte, 32)...)
+	expected = append(expected, common.LeftPadBytes([]byte{byte(len(transfer.Data))}, 32)...)
+	expected = append(expected, transfer.Data...)
+	expected = append(expected, 0)
+	expected = append(expected, fakeAddress(2).Bytes()...)
+	expected = append(expected, common.LeftPadBytes([]byte{5}, 32)...)
+	expected = append(expected, make([]byte, 32)...)
+	assert.Equal(t, expected, ms.Encode())
+
+	calldata, err := ms.Calldata()
+	assert.NoError(t, err)
+	assert.Equal(t, common.FromHex("0x8d80ff0a"), calldata[:4])
+	assert.True(t, bytes.Contains(calldata, expected))
+
+	ms.AddDelegateCall(RawMulticall{Address: fakeAddress(3)})
+	assert.Equal(t, common.HexToAddress(MultiSendAddress), ms.To())
+	assert.Equal(t, byte(MultiSendDelegateCall), ms.Encode()[len(expected)])
+}
+
+func TestMultiSendSimulate(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0xa0)
+	chain.contracts[token] = fakeERC20("A", 18)
+	mc := newFakeClient(t, chain)
+	erc20 := mustParseABI(t, erc20TestAbi)
+
+	// the safe holds more than the multicall contract, so the transfer only succeeds if the safe is msg.sender.
+	safe := fakeAddress(0xffff)
+	assert.Greater(t, fakeBalance(safe).Cmp(big.NewInt(60_000)), 0)
+	assert.Less(t, fakeBalance(mc.Address).Cmp(big.NewInt(60_000)), 0)
+	transfer, err := Describe[bool](token, erc20, "transfer", fakeAddress(1), big.NewInt(60_000))
+	assert.NoError(t, err)
+	tooMuch, err := Describe[bool](token, erc20, "transfer", fakeAddress(1), big.NewInt(70_000))
+	assert.NoError(t, err)
+
+	results, err := NewMultiSend(transfer.Raw(), tooMuch.Raw()).Simulate(mc, safe, nil)
+	assert.NoError(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, true, *results[0].Value.(*bool))
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "ERC20: transfer amount exceeds balance", results[1].Value.(*RevertError).Reason)
+
+	_, err = NewMultiSend().AddDelegateCall(transfer.Raw()).Simulate(mc, safe, nil)
+	assert.Error(t, err)
+
+	// the Safe sends every call, so calls from someone else are rejected.
+	chain.ethCalls = 0
+	fromOther := NewMultiSend(transfer.Raw(), transfer.WithFrom(fakeAddress(1)).Raw())
+	_, err = fromOther.Simulate(mc, safe, nil)
+	assert.ErrorContains(t, err, "transaction 1 (transfer) has a From")
+	_, err = fromOther.Calldata()
+	assert.ErrorContains(t, err, "transaction 1 (transfer) has a From")
+	assert.Equal(t, 0, chain.ethCalls)
+}
@@ -0,0 +1,230 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+var errStageNotRun = errors.New("pipeline has not run this stage yet")
+
+// A stage of a `Pipeline`. Implemented by `*Stage[T]`.
+type PipelineStage interface {
+	pipeline() *Pipeline
+	dependencies() []PipelineStage
+	finished() bool
+	failure() error
+	prepare() ([]RawMulticall, error)
+	resolve(results []Multicall3Result)
+	fail(err error)
+}
+
+/*
+ * Runs reads that depend on each other, e.g. `allPairsLength()` followed by `allPairs(i)`.
+ *
+ * Each stage builds its calls from the results of the stages it depends on. Stages whose dependencies are
+ * satisfied run together in shared aggregate3 batches, so independent stages never cost an extra round trip,
+ * and every stage reads from the same block.
+ *
+ *	p := multicall.NewPipeline(mc)
+ *	length := multicall.AddStage(p, func() ([]*multicall.MultiCallMetaData[big.Int], error) { ... })
+ *	pairs := multicall.Then(p, length, func(lengths []multicall.Result[big.Int]) ([]*multicall.MultiCallMetaData[common.Address], error) { ... })
+ *	err := p.Run()
+ *	addresses, err := pairs.Values()
+ */
+type Pipeline struct {
+	mc     *MulticallClient
+	stages []PipelineStage
+}
+
+// A typed stage of a `Pipeline`. Its results are available once the pipeline has run.
+type Stage[T any] struct {
+	owner   *Pipeline
+	deps    []PipelineStage
+	build   func() ([]*MultiCallMetaData[T], error)
+	calls   []*MultiCallMetaData[T]
+	results []Result[T]
+	err     error
+	done    bool
+}
+
+func NewPipeline(mc *MulticallClient) *Pipeline {
+	return &Pipeline{mc: mc}
+}
+
+// Adds a stage that runs once every stage in `deps` has finished. `build` usually reads the dependencies' results.
+func AddStage[T any](p *Pipeline, build func() ([]*MultiCallMetaData[T], error), deps ...PipelineStage) *Stage[T] {
+	stage := &Stage[T]{owner: p, deps: deps, build: build}
+	for _, dep := range deps {
+		if dep.pipeline() != p {
+			stage.fail(errors.New("stage depends on a stage from another pipeline"))
+		}
+	}
+	p.stages = append(p.stages, stage)
+	return stage
+}
+
+// Adds a stage whose calls are built from the results of `parent`.
+func Then[A any, B any](p *Pipeline, parent *Stage[A], build func([]Result[A]) ([]*MultiCallMetaData[B], error)) *Stage[B] {
+	return AddStage(p, func() ([]*MultiCallMetaData[B], error) {
+		results, err := parent.Results()
+		if err != nil {
+			return nil, err
+		}
+		return build(results)
+	}, parent)
+}
+
+func (p *Pipeline) Run() error {
+	return p.RunWithOptions(nil)
+}
+
+// Runs every stage. Returns the failures of stages that couldn't be built or executed, if any.
+func (p *Pipeline) RunWithOptions(options *bind.CallOpts) error {
+	pinned, err := p.mc.pinBlockNumber(p.mc.callOptions(options))
+	if err != nil {
+		return err
+	}
+
+	for {
+		ready := []PipelineStage{}
+		for _, stage := range p.stages {
+			if stage.finished() {
+				continue
+			}
+			if depErr := failedDependency(stage); depErr != nil {
+				stage.fail(fmt.Errorf("depends on a failed stage: %w", depErr))
+				continue
+			}
+			if allFinished(stage.dependencies()) {
+				ready = append(ready, stage)
+			}
+		}
+		if len(ready) == 0 {
+			break
+		}
+
+		// every ready stage shares the same batch.
+		calls := []RawMulticall{}
+		counts := make([]int, len(ready))
+		for i, stage := range ready {
+			stageCalls, err := stage.prepare()
+			if err != nil {
+				stage.fail(err)
+				continue
+			}
+			counts[i] = len(stageCalls)
+			calls = append(calls, stageCalls...)
+		}
+
+		results := []Multicall3Result{}
+		if len(calls) > 0 {
+			if results, err = executeMulticall(p.mc, pinned, calls); err != nil {
+				for _, stage := range ready {
+					if !stage.finished() {
+						stage.fail(err)
+					}
+				}
+				continue
+			}
+		}
+
+		offset := 0
+		for i, stage := range ready {
+			if stage.finished() {
+				continue
+			}
+			stage.resolve(results[offset : offset+counts[i]])
+			offset += counts[i]
+		}
+	}
+
+	errs := []error{}
+	for i, stage := range p.stages {
+		if err := stage.failure(); err != nil {
+			errs = append(errs, fmt.Errorf("stage %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Returns the stage's results, one per call it built.
+func (s *Stage[T]) Results() ([]Result[T], error) {
+	if !s.done {
+		return nil, errStageNotRun
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.results, nil
+}
+
+// Returns the stage's values, failing if any of its calls failed.
+func (s *Stage[T]) Values() ([]T, error) {
+	results, err := s.Results()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]T, len(results))
+	for i, res := range results {
+		if !res.Ok() {
+			return nil, fmt.Errorf("call %d failed: %w", i, res.Err())
+		}
+		values[i] = res.Value
+	}
+	return values, nil
+}
+
+func (s *Stage[T]) pipeline() *Pipeline           { return s.owner }
+func (s *Stage[T]) dependencies() []PipelineStage { return s.deps }
+func (s *Stage[T]) finished() bool                { return s.done }
+
+func (s *Stage[T]) failure() error {
+	if !s.done {
+		return nil
+	}
+	return s.err
+}
+
+func (s *Stage[T]) prepare() ([]RawMulticall, error) {
+	calls, err := s.build()
+	if err != nil {
+		return nil, err
+	}
+	s.calls = calls
+	return mapCollection(calls, func(md *MultiCallMetaData[T], _ uint64) RawMulticall {
+		return md.rawCall()
+	}), nil
+}
+
+func (s *Stage[T]) resolve(results []Multicall3Result) {
+	s.results = make([]Result[T], len(s.calls))
+	for i, call := range s.calls {
+		s.results[i] = decodeResult(call, results[i])
+	}
+	s.done = true
+}
+
+func (s *Stage[T]) fail(err error) {
+	s.err = err
+	s.done = true
+}
+
+func allFinished(stages []PipelineStage) bool {
+	for _, stage := range stages {
+		if !stage.finished() {
+			return false
+		}
+	}
+	return true
+}
+
+func failedDependency(stage PipelineStage) error {
+	for _, dep := range stage.dependencies() {
+		if err := dep.failure(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package multicall
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const factoryTestAbi = `[
+	{"type":"function","name":"allPairsLength","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"allPairs","stateMutability":"view","inputs":[{"name":"","type":"uint256"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"observedBlock","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+// fakeFactory has `pairs` pairs at addresses 0x1000+i. Every call to allPairsLength mines a block, so tests can
+// check that later stages still read from the pinned block.
+func fakeFactory(pairs int) fakeContract {
+	parsed := panicIfError(abi.JSON(strings.NewReader(factoryTestAbi)))
+	return abiContract(parsed, map[string]fakeMethod{
+		"allPairsLength": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			ctx.chain.blockNumber++
+			return []interface{}{big.NewInt(int64(pairs))}, nil
+		},
+		"allPairs": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			i := args[0].(*big.Int).Int64()
+			if i >= int64(pairs) {
+				return nil, revertWithReason("index out of bounds")
+			}
+			return []interface{}{fakeAddress(0x1000 + int(i))}, nil
+		},
+		"observedBlock": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{new(big.Int).SetUint64(ctx.block)}, nil
+		},
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	chain := newFakeChain()
+	factory := fakeAddress(0xfac)
+	token := fakeAddress(0x20)
+	chain.contracts[factory] = fakeFactory(4)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+
+	p := NewPipeline(mc)
+	length := AddStage(p, func() ([]*MultiCallMetaData[big.Int], error) {
+		call, err := DescribeSig[big.Int](factory, "allPairsLength()(uint256)")
+		return []*MultiCallMetaData[big.Int]{call}, err
+	})
+	// independent of `length`, so it shares the first batch.
+	supply := AddStage(p, func() ([]*MultiCallMetaData[big.Int], error) {
+		call, err := DescribeSig[big.Int](token, "totalSupply()(uint256)")
+		return []*MultiCallMetaData[big.Int]{call}, err
+	})
+	pairs := Then(p, length, func(lengths []Result[big.Int]) ([]*MultiCallMetaData[common.Address], error) {
+		count, err := lengths[0].Unwrap()
+		if err != nil {
+			return nil, err
+		}
+		calls := []*MultiCallMetaData[common.Address]{}
+		for i := int64(0); i < count.Int64(); i++ {
+			call, err := DescribeSig[common.Address](factory, "allPairs(uint256)(address)", big.NewInt(i))
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, call)
+		}
+		return calls, nil
+	})
+	observed := AddStage(p, func() ([]*MultiCallMetaData[big.Int], error) {
+		call, err := DescribeSig[big.Int](factory, "observedBlock()(uint256)")
+		return []*MultiCallMetaData[big.Int]{call}, err
+	}, pairs, supply)
+
+	_, err := pairs.Results()
+	assert.Error(t, err) // not run yet
+
+	assert.NoError(t, p.Run())
+	assert.Equal(t, 3, chain.ethCalls) // one batch per level, at the block pinned with eth_blockNumber
+
+	addresses, err := pairs.Values()
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{fakeAddress(0x1000), fakeAddress(0x1001), fakeAddress(0x1002), fakeAddress(0x1003)}, addresses)
+
+	supplies, err := supply.Values()
+	assert.NoError(t, err)
+	assert.Equal(t, *big.NewInt(1_000_000), supplies[0])
+
+	// allPairsLength mined a block, but later stages still read from the block the pipeline started on.
+	blocks, err := observed.Values()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), blocks[0].Uint64())
+	assert.Equal(t, uint64(101), chain.blockNumber)
+}
+
+func TestPipeline_FailuresPropagate(t *testing.T) {
+	chain := newFakeChain()
+	mc := newFakeClient(t, chain)
+
+	p := NewPipeline(mc)
+	broken := AddStage(p, func() ([]*MultiCallMetaData[big.Int], error) {
+		call, err := DescribeSig[big.Int](fakeAddress(1), "notASignature")
+		return []*MultiCallMetaData[big.Int]{call}, err
+	})
+	dependent := Then(p, broken, func(results []Result[big.Int]) ([]*MultiCallMetaData[big.Int], error) {
+		t.Fatal("should not build a stage whose dependency failed")
+		return nil, nil
+	})
+	empty := AddStage(p, func() ([]*MultiCallMetaData[big.Int], error) {
+		return nil, nil
+	})
+
+	assert.Error(t, p.Run())
+	_, err := broken.Results()
+	assert.Error(t, err)
+	_, err = dependent.Results()
+	assert.Error(t, err)
+	results, err := empty.Results()
+	assert.NoError(t, err)
+	assert.Len(t, results, 0)
+}
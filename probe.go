@@ -0,0 +1,149 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Whether a probed call would succeed, and if not, why.
+type ProbeResult struct {
+	Success bool
+	// Why the call reverted, with the ABI's custom errors decoded. Nil on success.
+	Revert *RevertError
+}
+
+// How `DoProbes()` executes its calls.
+type ProbeOptions struct {
+	CallOpts *bind.CallOpts
+	/*
+	 * The msg.sender of the probed calls. By default they come from the multicall contract; with `From` set, the
+	 * batch runs with Multicall3's code overridden onto `From`, so every call sees `From` as its sender.
+	 */
+	From *common.Address
+	// State overrides applied to the eth_call, e.g. to give an account a balance or an allowance.
+	StateOverride map[common.Address]gethclient.OverrideAccount
+}
+
+/*
+ * Describes a call whose only purpose is finding out whether it would revert, e.g. `withdraw(x)` for each of
+ * a set of accounts. The call itself never fails: its result reports success or the decoded revert.
+ */
+func DescribeProbe(contractAddress common.Address, contractAbi abi.ABI, method string, params ...interface{}) (*MultiCallMetaData[ProbeResult], error) {
+	call, err := DescribeWithDeserialize(contractAddress, contractAbi, func(b []byte) (*ProbeResult, error) {
+		return &ProbeResult{Success: true}, nil
+	}, method, params...)
+	if err != nil {
+		return nil, err
+	}
+	customErrors := abiErrors(contractAbi)
+	call.DecodeResult = func(res Multicall3Result) (*ProbeResult, error) {
+		if res.Success {
+			return &ProbeResult{Success: true}, nil
+		}
+		return &ProbeResult{Revert: DecodeRevert(res.ReturnData, customErrors...)}, nil
+	}
+	return call, nil
+}
+
+// Runs probes in as few eth_calls as possible, optionally as another sender or on top of a state override.
+func DoProbes(mc *MulticallClient, options *ProbeOptions, probes ...*MultiCallMetaData[ProbeResult]) ([]ProbeResult, error) {
+	if options == nil {
+		options = &ProbeOptions{}
+	}
+	if options.From == nil && len(options.StateOverride) == 0 {
+		return DoManyValuesWithOptions(mc, options.CallOpts, probes...)
+	}
+
+	calls := mapCollection(probes, func(md *MultiCallMetaData[ProbeResult], index uint64) RawMulticall {
+		return md.rawCall()
+	})
+	res, err := executeMulticallWith(mc, options.CallOpts, calls, mc.aggregate3WithOverrides(options.From, options.StateOverride))
+	if err != nil {
+		return nil, fmt.Errorf("multicall failed: %s", err.Error())
+	}
+
+	results := make([]ProbeResult, len(probes))
+	for i, probe := range probes {
+		value, err := decodeResult(probe, res[i]).Unwrap()
+		if err != nil {
+			return nil, fmt.Errorf("call %d failed: %w", i, err)
+		}
+		results[i] = value
+	}
+	return results, nil
+}
+
+/*
+ * aggregate3WithOverrides returns an aggregator that makes its eth_call with state overrides. When `from` is set,
+ * Multicall3's code is overridden onto that address and aggregate3 is called there, so it's the msg.sender of
+ * every call in the batch.
+ */
+func (mc *MulticallClient) aggregate3WithOverrides(from *common.Address, overrides map[common.Address]gethclient.OverrideAccount) aggregator {
+	return func(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+		method, data, value, err := mc.packAggregate3(calls)
+		if err != nil {
+			return nil, err
+		}
+		out, err := mc.callWithOverrides(opts, from, value, data, overrides)
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %s", method, err)
+		}
+		return mc.unpackAggregate3(method, out)
+	}
+}
+
+// callWithOverrides makes a raw eth_call of the multicall contract (or of its code, run at `from`).
+func (mc *MulticallClient) callWithOverrides(opts *bind.CallOpts, from *common.Address, value *big.Int, data []byte, overrides map[common.Address]gethclient.OverrideAccount) ([]byte, error) {
+	if mc.Client == nil {
+		return nil, ErrNoClient
+	}
+	if opts == nil {
+		opts = &bind.CallOpts{}
+	}
+	if opts.BlockHash != (common.Hash{}) {
+		return nil, errors.New("calls with state overrides can't be made at a block hash")
+	}
+	ctx := callContext(mc, opts)
+	block := opts.BlockNumber
+	if opts.Pending {
+		block = big.NewInt(int64(rpc.PendingBlockNumber))
+	}
+
+	target := mc.Address
+	msg := ethereum.CallMsg{From: opts.From, To: &target, Value: value, Data: data}
+	allOverrides := make(map[common.Address]gethclient.OverrideAccount, len(overrides)+1)
+	for address, account := range overrides {
+		allOverrides[address] = account
+	}
+	if from != nil {
+		code, err := mc.Client.CodeAt(ctx, mc.Address, block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the multicall contract's code: %s", err.Error())
+		}
+		account := allOverrides[*from]
+		account.Code = code
+		allOverrides[*from] = account
+		target = *from
+		msg.From = *from
+	}
+	return gethclient.New(mc.Client.Client()).CallContract(ctx, msg, block, &allOverrides)
+}
+
+func callContext(mc *MulticallClient, opts *bind.CallOpts) context.Context {
+	if opts != nil && opts.Context != nil {
+		return opts.Context
+	}
+	if mc.Context != nil {
+		return mc.Context
+	}
+	return context.Background()
+}
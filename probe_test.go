@@ -0,0 +1,70 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoProbes(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x70)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+	erc20 := mustParseABI(t, erc20TestAbi)
+
+	// the multicall contract's fake balance is 0xca11; transfers above it revert.
+	small, err := DescribeProbe(token, erc20, "transfer", fakeAddress(1), big.NewInt(100))
+	assert.NoError(t, err)
+	large, err := DescribeProbe(token, erc20, "transfer", fakeAddress(1), big.NewInt(0xca12))
+	assert.NoError(t, err)
+
+	results, err := DoProbes(mc, nil, small, large)
+	assert.NoError(t, err)
+	assert.Equal(t, ProbeResult{Success: true}, results[0])
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "ERC20: transfer amount exceeds balance", results[1].Revert.Reason)
+
+	// probes are ordinary calls, so they batch with anything else.
+	values, err := DoManyValues(mc, small, large)
+	assert.NoError(t, err)
+	assert.Equal(t, results, values)
+
+	// as a holder with a balance of 0xffff, both transfers go through.
+	holder := fakeAddress(0xffff)
+	results, err = DoProbes(mc, &ProbeOptions{From: &holder}, small, large)
+	assert.NoError(t, err)
+	assert.Equal(t, []ProbeResult{{Success: true}, {Success: true}}, results)
+}
+
+func TestDoProbes_StateOverride(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x70)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+	erc20 := mustParseABI(t, erc20TestAbi)
+
+	// a token that isn't deployed yet, simulated by overriding its code.
+	undeployed := fakeAddress(0x71)
+	probe, err := DescribeProbe(undeployed, erc20, "transfer", fakeAddress(1), big.NewInt(0xca12))
+	assert.NoError(t, err)
+	symbol, err := DescribeProbe(undeployed, erc20, "symbol")
+	assert.NoError(t, err)
+
+	results, err := DoProbes(mc, &ProbeOptions{
+		StateOverride: map[common.Address]gethclient.OverrideAccount{undeployed: {Code: fakeCode(token)}},
+	}, probe, symbol)
+	assert.NoError(t, err)
+	assert.False(t, results[0].Success)
+	assert.True(t, results[1].Success)
+
+	_, err = DoProbes(mc, &ProbeOptions{
+		CallOpts:      &bind.CallOpts{BlockHash: common.Hash{1}},
+		StateOverride: map[common.Address]gethclient.OverrideAccount{undeployed: {Code: fakeCode(token)}},
+	}, probe)
+	assert.Error(t, err)
+}
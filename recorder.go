@@ -0,0 +1,184 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	errCallRecorded   = errors.New("call recorded for the next multicall")
+	errNotFlushed     = errors.New("recorded call has not been flushed yet")
+	errNoRecordedCall = errors.New("CallRecorder used outside of Record()")
+)
+
+type recordedEntry struct {
+	captures []RawMulticall
+	replay   func()
+	// resolves the recorded call with an error, when its multicall couldn't be made.
+	fail func(err error)
+}
+
+/**
+ * A bind.ContractCaller that lets existing abigen bindings participate in a multicall.
+ *
+ * Bind your generated caller to the recorder instead of an ethclient, wrap each binding call in `Record()`,
+ * and then `Flush()` the recorder. Flushing runs every captured call in one multicall and then replays each
+ * recorded function against the results, so the binding decodes its own outputs exactly as it normally would.
+ *
+ *	rec := multicall.NewCallRecorder()
+ *	pod, _ := NewEigenPodCaller(podAddress, rec)
+ *	info := multicall.Record(rec, func() (IEigenPodValidatorInfo, error) {
+ *		return pod.ValidatorPubkeyToInfo(nil, pubkey)
+ *	})
+ *	err := rec.Flush(mc)
+ *	value, err := info.Result()
+ *
+ * Recorded functions run twice (once to capture, once to replay), so they should only invoke the binding.
+ * CallOpts passed to the binding are ignored; use `FlushWithOptions()` to pick a block. A CallRecorder is
+ * not safe for concurrent use.
+ */
+type CallRecorder struct {
+	entries   []*recordedEntry
+	recording *recordedEntry
+	replaying []DeserializedMulticall3Result
+}
+
+// The eventual outcome of a call captured by `Record()`, available once the recorder has been flushed.
+type RecordedCall[T any] struct {
+	value T
+	err   error
+	done  bool
+}
+
+func NewCallRecorder() *CallRecorder {
+	return &CallRecorder{}
+}
+
+// Returns the value the binding produced when the call was replayed.
+func (rc *RecordedCall[T]) Result() (T, error) {
+	if !rc.done {
+		return *new(T), errNotFlushed
+	}
+	return rc.value, rc.err
+}
+
+// Captures the contract calls made by `call` so they can be executed by the next `Flush()`.
+func Record[T any](r *CallRecorder, call func() (T, error)) *RecordedCall[T] {
+	recorded := &RecordedCall[T]{}
+	entry := &recordedEntry{}
+
+	r.recording = entry
+	_, err := call()
+	r.recording = nil
+
+	if len(entry.captures) == 0 {
+		// the binding never reached the backend (e.g. the arguments couldn't be packed), so there's nothing to batch.
+		recorded.err = err
+		recorded.done = true
+		return recorded
+	}
+
+	entry.replay = func() {
+		recorded.value, recorded.err = call()
+		recorded.done = true
+	}
+	entry.fail = func(err error) {
+		recorded.err = err
+		recorded.done = true
+	}
+	r.entries = append(r.entries, entry)
+	return recorded
+}
+
+// Number of calls waiting for the next `Flush()`.
+func (r *CallRecorder) Pending() int {
+	total := 0
+	for _, entry := range r.entries {
+		total += len(entry.captures)
+	}
+	return total
+}
+
+func (r *CallRecorder) Flush(mc *MulticallClient) error {
+	return r.FlushWithOptions(mc, nil)
+}
+
+// Executes every recorded call in one multicall and resolves the corresponding `RecordedCall`s. If the multicall
+// fails, they're resolved with its error.
+func (r *CallRecorder) FlushWithOptions(mc *MulticallClient, options *bind.CallOpts) error {
+	entries := r.entries
+	r.entries = nil
+	if len(entries) == 0 {
+		return nil
+	}
+
+	calls := []RawMulticall{}
+	for _, entry := range entries {
+		calls = append(calls, entry.captures...)
+	}
+
+	res, err := doMultiCallMany(mc, options, calls...)
+	if err != nil {
+		err = fmt.Errorf("multicall failed: %w", err)
+		for _, entry := range entries {
+			entry.fail(err)
+		}
+		return err
+	}
+
+	offset := 0
+	for _, entry := range entries {
+		r.replaying = res[offset : offset+len(entry.captures)]
+		offset += len(entry.captures)
+		entry.replay()
+	}
+	r.replaying = nil
+	return nil
+}
+
+func (r *CallRecorder) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if r.recording != nil {
+		if call.To == nil {
+			return nil, errors.New("cannot record a contract creation")
+		}
+		functionName := ""
+		if len(call.Data) >= 4 {
+			functionName = hexutil.Encode(call.Data[:4])
+		}
+		r.recording.captures = append(r.recording.captures, RawMulticall{
+			Address:      *call.To,
+			Data:         call.Data,
+			FunctionName: functionName,
+			Deserialize: func(b []byte) (any, error) {
+				return b, nil
+			},
+		})
+		return nil, errCallRecorded
+	}
+
+	if len(r.replaying) > 0 {
+		res := r.replaying[0]
+		r.replaying = r.replaying[1:]
+		if !res.Success {
+			if err, ok := res.Value.(error); ok {
+				return nil, err
+			}
+			return nil, ErrCallFailed
+		}
+		return res.Value.([]byte), nil
+	}
+
+	return nil, errNoRecordedCall
+}
+
+// Bindings only ask for code when a call returned no data, which multicall results never do.
+func (r *CallRecorder) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
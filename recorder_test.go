@@ -0,0 +1,128 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeEigenPod(owner common.Address) fakeContract {
+	eigenpodAbi, _ := EigenPodMetaData.GetAbi()
+	return abiContract(*eigenpodAbi, map[string]fakeMethod{
+		"podOwner": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{owner}, nil
+		},
+		"validatorPubkeyToInfo": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			pubkey := args[0].([]byte)
+			if len(pubkey) != 48 {
+				return nil, revertWithReason("invalid pubkey length")
+			}
+			return []interface{}{IEigenPodValidatorInfo{
+				ValidatorIndex:      uint64(pubkey[0]),
+				RestakedBalanceGwei: 32_000_000_000,
+				LastCheckpointedAt:  1_700_000_000,
+				Status:              1,
+			}}, nil
+		},
+	})
+}
+
+func TestCallRecorder(t *testing.T) {
+	chain := newFakeChain()
+	podAddress := fakeAddress(0xe1)
+	owner := fakeAddress(0x0e)
+	chain.contracts[podAddress] = fakeEigenPod(owner)
+	mc := newFakeClient(t, chain)
+
+	rec := NewCallRecorder()
+	pod, err := NewEigenPodCaller(podAddress, rec)
+	assert.NoError(t, err)
+
+	infos := make([]*RecordedCall[IEigenPodValidatorInfo], 3)
+	for i := range infos {
+		pubkey := make([]byte, 48)
+		pubkey[0] = byte(i + 1)
+		infos[i] = Record(rec, func() (IEigenPodValidatorInfo, error) {
+			return pod.ValidatorPubkeyToInfo(nil, pubkey)
+		})
+	}
+	podOwner := Record(rec, func() (common.Address, error) {
+		return pod.PodOwner(nil)
+	})
+	badKey := Record(rec, func() (IEigenPodValidatorInfo, error) {
+		return pod.ValidatorPubkeyToInfo(nil, []byte{1, 2, 3})
+	})
+
+	// nothing has been executed yet.
+	_, err = podOwner.Result()
+	assert.Error(t, err)
+	assert.Equal(t, 5, rec.Pending())
+	assert.Equal(t, 0, chain.ethCalls)
+
+	assert.NoError(t, rec.Flush(mc))
+	assert.Equal(t, 1, chain.ethCalls) // everything went out in one aggregate3
+	assert.Equal(t, 0, rec.Pending())
+
+	for i, recorded := range infos {
+		info, err := recorded.Result()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(i+1), info.ValidatorIndex)
+		assert.Equal(t, uint64(32_000_000_000), info.RestakedBalanceGwei)
+		assert.Equal(t, uint8(1), info.Status)
+	}
+
+	ownerValue, err := podOwner.Result()
+	assert.NoError(t, err)
+	assert.Equal(t, owner, ownerValue)
+
+	_, err = badKey.Result()
+	assert.Error(t, err)
+}
+
+func TestCallRecorder_PackingErrorsResolveImmediately(t *testing.T) {
+	rec := NewCallRecorder()
+	pod, err := NewEigenPodCaller(fakeAddress(0xe1), rec)
+	assert.NoError(t, err)
+
+	// checkpointBalanceExitedGwei takes a uint64, so a big.Int can't be packed.
+	recorded := Record(rec, func() (uint64, error) {
+		var out []interface{}
+		err := pod.contract.Call(nil, &out, "checkpointBalanceExitedGwei", big.NewInt(1))
+		return 0, err
+	})
+	_, err = recorded.Result()
+	assert.Error(t, err)
+	assert.Equal(t, 0, rec.Pending())
+
+	// calling the binding outside of Record() is an error rather than a silent no-op.
+	_, err = pod.PodOwner(nil)
+	assert.Error(t, err)
+}
+
+func TestCallRecorder_FailedFlushResolvesCalls(t *testing.T) {
+	chain := newFakeChain()
+	podAddress := fakeAddress(0xe1)
+	chain.contracts[podAddress] = fakeEigenPod(fakeAddress(0x0e))
+	mc := newFakeClient(t, chain)
+
+	rec := NewCallRecorder()
+	pod, err := NewEigenPodCaller(podAddress, rec)
+	assert.NoError(t, err)
+	podOwner := Record(rec, func() (common.Address, error) {
+		return pod.PodOwner(nil)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = rec.FlushWithOptions(mc, &bind.CallOpts{Context: ctx})
+	assert.ErrorContains(t, err, "multicall failed")
+	assert.Equal(t, 0, rec.Pending())
+
+	// the call isn't left waiting for a flush that will never come.
+	_, resultErr := podOwner.Result()
+	assert.Equal(t, err, resultErr)
+}
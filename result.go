@@ -0,0 +1,119 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// The outcome of a single call. The zero value is a failed result with no error attached.
+type Result[A any] struct {
+	Value A
+	err   error
+	ok    bool
+}
+
+func okResult[A any](value A) Result[A] {
+	return Result[A]{Value: value, ok: true}
+}
+
+func failedResult[A any](err error) Result[A] {
+	return Result[A]{err: err}
+}
+
+// Whether the call succeeded and was deserialized.
+func (r Result[A]) Ok() bool {
+	return r.ok
+}
+
+// Why the call failed, or nil if it succeeded.
+func (r Result[A]) Err() error {
+	if r.ok {
+		return nil
+	}
+	if r.err == nil {
+		return ErrCallFailed
+	}
+	return r.err
+}
+
+// Returns the value and the error in the usual Go style.
+func (r Result[A]) Unwrap() (A, error) {
+	return r.Value, r.Err()
+}
+
+// Returns the value if the call succeeded, and `fallback` otherwise.
+func (r Result[A]) OrElse(fallback A) A {
+	if r.ok {
+		return r.Value
+	}
+	return fallback
+}
+
+// Like `DoMany()`, but returns the values themselves instead of a pointer to a slice of pointers.
+func DoManyValues[A any](mc *MulticallClient, requests ...*MultiCallMetaData[A]) ([]A, error) {
+	return DoManyValuesWithOptions(mc, nil, requests...)
+}
+
+func DoManyValuesWithOptions[A any](mc *MulticallClient, options *bind.CallOpts, requests ...*MultiCallMetaData[A]) ([]A, error) {
+	results, err := DoManyResultsWithOptions(mc, options, requests...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]A, len(results))
+	for i, res := range results {
+		if !res.ok {
+			return nil, fmt.Errorf("call %d failed: %w", i, res.Err())
+		}
+		values[i] = res.Value
+	}
+	return values, nil
+}
+
+// Like `DoManyAllowFailures()`, but returns one `Result` per request, by value.
+func DoManyResults[A any](mc *MulticallClient, requests ...*MultiCallMetaData[A]) ([]Result[A], error) {
+	return DoManyResultsWithOptions(mc, nil, requests...)
+}
+
+func DoManyResultsWithOptions[A any](mc *MulticallClient, options *bind.CallOpts, requests ...*MultiCallMetaData[A]) ([]Result[A], error) {
+	calls := mapCollection(requests, func(md *MultiCallMetaData[A], index uint64) RawMulticall {
+		return md.rawCall()
+	})
+	res, err := executeMulticall(mc, options, calls)
+	if err != nil {
+		return nil, fmt.Errorf("multicall failed: %w", err)
+	}
+
+	return mapCollection(requests, func(md *MultiCallMetaData[A], i uint64) Result[A] {
+		return decodeResult(md, res[i])
+	}), nil
+}
+
+// decodeResult deserializes a raw result straight into A, without boxing it through `any` like `Raw()` does.
+func decodeResult[A any](md *MultiCallMetaData[A], res Multicall3Result) Result[A] {
+	if md.DecodeResult != nil {
+		val, err := md.DecodeResult(res)
+		if err != nil {
+			return failedResult[A](err)
+		}
+		if val == nil {
+			return failedResult[A](fmt.Errorf("%s returned a value that couldn't be deserialized", md.FunctionName))
+		}
+		return okResult(*val)
+	}
+	if !res.Success {
+		return failedResult[A](DecodeRevert(res.ReturnData))
+	}
+	if len(res.ReturnData) == 0 {
+		return failedResult[A](ErrNoData)
+	}
+	val, err := md.Deserialize(res.ReturnData)
+	if err != nil {
+		return failedResult[A](err)
+	}
+	if val == nil {
+		return failedResult[A](fmt.Errorf("%s returned a value that couldn't be deserialized", md.FunctionName))
+	}
+	return okResult(*val)
+}
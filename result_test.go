@@ -0,0 +1,66 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultHelpers(t *testing.T) {
+	ok := okResult(42)
+	assert.True(t, ok.Ok())
+	assert.NoError(t, ok.Err())
+	assert.Equal(t, 42, ok.OrElse(7))
+	value, err := ok.Unwrap()
+	assert.Equal(t, 42, value)
+	assert.NoError(t, err)
+
+	failed := failedResult[int](ErrNoData)
+	assert.False(t, failed.Ok())
+	assert.ErrorIs(t, failed.Err(), ErrNoData)
+	assert.Equal(t, 7, failed.OrElse(7))
+	_, err = failed.Unwrap()
+	assert.ErrorIs(t, err, ErrNoData)
+
+	// the zero value is a failure.
+	var zero Result[int]
+	assert.False(t, zero.Ok())
+	assert.ErrorIs(t, zero.Err(), ErrCallFailed)
+}
+
+func TestDoManyValuesAndResults(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x20)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+
+	holders := []common.Address{fakeAddress(0x101), fakeAddress(0x202), common.HexToAddress("0xdead000000000000000000000000000000000001")}
+	calls := mapCollection(holders, func(holder common.Address, _ uint64) *MultiCallMetaData[big.Int] {
+		return panicIfError(DescribeSig[big.Int](token, "balanceOf(address)(uint256)", holder))
+	})
+
+	values, err := DoManyValues(mc, calls[:2]...)
+	assert.NoError(t, err)
+	assert.Equal(t, []big.Int{*fakeBalance(holders[0]), *fakeBalance(holders[1])}, values)
+
+	// one failure fails the whole request, and says which call it was.
+	values, err = DoManyValues(mc, calls...)
+	assert.Nil(t, values)
+	assert.ErrorIs(t, err, ErrCallFailed)
+	assert.Contains(t, err.Error(), "call 2")
+
+	results, err := DoManyResults(mc, calls...)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.True(t, results[0].Ok())
+	assert.Equal(t, *fakeBalance(holders[1]), results[1].Value)
+	assert.True(t, errors.Is(results[2].Err(), ErrCallFailed))
+
+	// calls to accounts without code don't return anything.
+	noCode, err := DoManyResults(mc, panicIfError(DescribeSig[big.Int](fakeAddress(0x999), "totalSupply()(uint256)")))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, noCode[0].Err(), ErrNoData)
+}
@@ -0,0 +1,78 @@
+package multicall
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0} // Error(string)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71} // Panic(uint256)
+)
+
+// A call that reverted. `errors.Is(err, ErrCallFailed)` holds for every RevertError.
+type RevertError struct {
+	// The raw revert data, which may be empty.
+	Data []byte
+	// "Error" for `require(cond, reason)`, "Panic" for assertion failures, or the name of a custom error.
+	// Empty when the data couldn't be decoded.
+	Name string
+	// A human-readable reason, if one could be decoded.
+	Reason string
+	// The arguments of a custom error.
+	Args []interface{}
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.Reason != "":
+		return fmt.Sprintf("%s: %s", ErrCallFailed.Error(), e.Reason)
+	case e.Name != "":
+		return fmt.Sprintf("%s: %s%v", ErrCallFailed.Error(), e.Name, e.Args)
+	case len(e.Data) > 0:
+		return fmt.Sprintf("%s: %s", ErrCallFailed.Error(), hexutil.Encode(e.Data))
+	default:
+		return ErrCallFailed.Error()
+	}
+}
+
+func (e *RevertError) Unwrap() error {
+	return ErrCallFailed
+}
+
+/*
+ * Decodes the data a call reverted with. Standard `Error(string)` and `Panic(uint256)` reverts are always
+ * recognized; custom errors are recognized when their definitions are passed in `customErrors`.
+ */
+func DecodeRevert(data []byte, customErrors ...abi.Error) *RevertError {
+	revert := &RevertError{Data: data}
+	if len(data) < 4 {
+		return revert
+	}
+
+	switch {
+	case bytes.Equal(data[:4], errorSelector), bytes.Equal(data[:4], panicSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			revert.Reason = reason
+			revert.Name = "Error"
+			if bytes.Equal(data[:4], panicSelector) {
+				revert.Name = "Panic"
+			}
+		}
+	default:
+		for _, custom := range customErrors {
+			if !bytes.Equal(data[:4], custom.ID[:4]) {
+				continue
+			}
+			if args, err := custom.Inputs.Unpack(data[4:]); err == nil {
+				revert.Name = custom.Name
+				revert.Args = args
+			}
+			break
+		}
+	}
+	return revert
+}
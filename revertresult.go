@@ -0,0 +1,77 @@
+package multicall
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/*
+ * Describes a call to a method that hands back its results by reverting with them, like Uniswap's Quoter, or a
+ * non-view method only meant to be used with eth_call.
+ *
+ * The revert data is decoded as the method's outputs, exactly as if it had been returned. A normal return is
+ * decoded the same way. Reverts with `Error(string)`, `Panic(uint256)` or a custom error of the ABI are still
+ * reported as failures.
+ */
+func DescribeRevert[T any](contractAddress common.Address, contractAbi abi.ABI, method string, params ...interface{}) (*MultiCallMetaData[T], error) {
+	call, err := Describe[T](contractAddress, contractAbi, method, params...)
+	if err != nil {
+		return nil, err
+	}
+	customErrors := abiErrors(contractAbi)
+	call.DecodeResult = func(res Multicall3Result) (*T, error) {
+		data := res.ReturnData
+		switch {
+		case len(data) == 0:
+			if res.Success {
+				return nil, ErrNoData
+			}
+			return nil, DecodeRevert(data)
+		// abi-encoded outputs are whole words, whereas errors start with a 4 byte selector.
+		case !res.Success && len(data)%32 != 0:
+			return nil, DecodeRevert(data, customErrors...)
+		}
+		return call.Deserialize(data)
+	}
+	return call, nil
+}
+
+/*
+ * Describes a call to a method that reports its results by reverting with the custom error `errorName`, such as
+ * `error Quote(uint256 amountOut, uint256 gasEstimate)`. The error's arguments are decoded into T like outputs
+ * would be by `DescribeSig()`: directly for a single argument, by name or position into a struct otherwise.
+ *
+ * Returning normally, or reverting with anything else, is a failure.
+ */
+func DescribeRevertError[T any](contractAddress common.Address, contractAbi abi.ABI, method string, errorName string, params ...interface{}) (*MultiCallMetaData[T], error) {
+	resultError, ok := contractAbi.Errors[errorName]
+	if !ok {
+		return nil, fmt.Errorf("error '%s' not found", errorName)
+	}
+	call, err := Describe[T](contractAddress, contractAbi, method, params...)
+	if err != nil {
+		return nil, err
+	}
+	customErrors := abiErrors(contractAbi)
+	call.DecodeResult = func(res Multicall3Result) (*T, error) {
+		if res.Success {
+			return nil, fmt.Errorf("%s returned instead of reverting with %s", method, errorName)
+		}
+		if len(res.ReturnData) < 4 || !bytes.Equal(res.ReturnData[:4], resultError.ID[:4]) {
+			return nil, DecodeRevert(res.ReturnData, customErrors...)
+		}
+		return unpackOutputs[T](resultError.Inputs, res.ReturnData[4:])
+	}
+	return call, nil
+}
+
+func abiErrors(contractAbi abi.ABI) []abi.Error {
+	customErrors := make([]abi.Error, 0, len(contractAbi.Errors))
+	for _, e := range contractAbi.Errors {
+		customErrors = append(customErrors, e)
+	}
+	return customErrors
+}
@@ -0,0 +1,129 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const quoterTestAbi = `[
+	{"type":"function","name":"quoteExactInput","stateMutability":"nonpayable","inputs":[{"name":"amountIn","type":"uint256"}],"outputs":[
+		{"name":"amountOut","type":"uint256"},{"name":"sqrtPriceX96After","type":"uint160"}]},
+	{"type":"function","name":"quote","stateMutability":"nonpayable","inputs":[{"name":"amountIn","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"lens","stateMutability":"nonpayable","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"error","name":"Quote","inputs":[{"name":"amountOut","type":"uint256"},{"name":"gasEstimate","type":"uint256"}]},
+	{"type":"error","name":"NoLiquidity","inputs":[]}
+]`
+
+type quoteResult struct {
+	AmountOut         *big.Int
+	SqrtPriceX96After *big.Int
+}
+
+type quoteError struct {
+	AmountOut   *big.Int
+	GasEstimate *big.Int
+}
+
+// fakeQuoter quotes twice the input, reporting it through revert data. Inputs over 1000 have no liquidity, and an
+// input of 0 fails with a plain reason.
+func fakeQuoter(t *testing.T) fakeContract {
+	parsed := mustParseABI(t, quoterTestAbi)
+	noLiquidity := parsed.Errors["NoLiquidity"]
+	fail := func(amountIn *big.Int) error {
+		if amountIn.Sign() == 0 {
+			return revertWithReason("zero input")
+		}
+		if amountIn.Cmp(big.NewInt(1000)) > 0 {
+			return &fakeRevert{data: noLiquidity.ID[:4]}
+		}
+		return nil
+	}
+	return abiContract(parsed, map[string]fakeMethod{
+		"quoteExactInput": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			amountIn := args[0].(*big.Int)
+			if err := fail(amountIn); err != nil {
+				return nil, err
+			}
+			encoded, _ := parsed.Methods["quoteExactInput"].Outputs.Pack(new(big.Int).Mul(amountIn, big.NewInt(2)), big.NewInt(79))
+			return nil, &fakeRevert{data: encoded}
+		},
+		"quote": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			amountIn := args[0].(*big.Int)
+			if err := fail(amountIn); err != nil {
+				return nil, err
+			}
+			quote := parsed.Errors["Quote"]
+			encoded, _ := quote.Inputs.Pack(new(big.Int).Mul(amountIn, big.NewInt(2)), big.NewInt(21000))
+			return nil, &fakeRevert{data: append(append([]byte{}, quote.ID[:4]...), encoded...)}
+		},
+		"lens": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(5)}, nil
+		},
+	})
+}
+
+func TestDescribeRevert(t *testing.T) {
+	chain := newFakeChain()
+	quoter := fakeAddress(0x9a)
+	chain.contracts[quoter] = fakeQuoter(t)
+	mc := newFakeClient(t, chain)
+	quoterAbi := mustParseABI(t, quoterTestAbi)
+
+	calls := []*MultiCallMetaData[quoteResult]{}
+	for _, amount := range []int64{10, 0, 5000} {
+		call, err := DescribeRevert[quoteResult](quoter, quoterAbi, "quoteExactInput", big.NewInt(amount))
+		assert.NoError(t, err)
+		calls = append(calls, call)
+	}
+	results, err := DoManyResults(mc, calls...)
+	assert.NoError(t, err)
+	assert.Equal(t, quoteResult{AmountOut: big.NewInt(20), SqrtPriceX96After: big.NewInt(79)}, results[0].Value)
+
+	// real errors are still failures.
+	var revert *RevertError
+	assert.True(t, errors.As(results[1].Err(), &revert))
+	assert.Equal(t, "zero input", revert.Reason)
+	assert.True(t, errors.As(results[2].Err(), &revert))
+	assert.Equal(t, "NoLiquidity", revert.Name)
+
+	// eth_call-only methods that return normally work too, including through the older APIs.
+	lens, err := DescribeRevert[big.Int](quoter, quoterAbi, "lens")
+	assert.NoError(t, err)
+	values, err := DoMany(mc, lens)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(5), (*values)[0])
+
+	doubled := Map(calls[0], func(q *quoteResult) (string, error) { return q.AmountOut.String(), nil })
+	mapped, err := DoManyValues(mc, doubled)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20"}, mapped)
+}
+
+func TestDescribeRevertError(t *testing.T) {
+	chain := newFakeChain()
+	quoter := fakeAddress(0x9a)
+	chain.contracts[quoter] = fakeQuoter(t)
+	mc := newFakeClient(t, chain)
+	quoterAbi := mustParseABI(t, quoterTestAbi)
+
+	quote, err := DescribeRevertError[quoteError](quoter, quoterAbi, "quote", "Quote", big.NewInt(7))
+	assert.NoError(t, err)
+	noLiquidity, err := DescribeRevertError[quoteError](quoter, quoterAbi, "quote", "Quote", big.NewInt(7000))
+	assert.NoError(t, err)
+	returned, err := DescribeRevertError[quoteError](quoter, quoterAbi, "lens", "Quote")
+	assert.NoError(t, err)
+
+	results, err := DoManyResults(mc, quote, noLiquidity, returned)
+	assert.NoError(t, err)
+	assert.Equal(t, quoteError{AmountOut: big.NewInt(14), GasEstimate: big.NewInt(21000)}, results[0].Value)
+	var revert *RevertError
+	assert.True(t, errors.As(results[1].Err(), &revert))
+	assert.Equal(t, "NoLiquidity", revert.Name)
+	assert.ErrorContains(t, results[2].Err(), "returned instead of reverting")
+
+	_, err = DescribeRevertError[quoteError](quoter, quoterAbi, "quote", "Missing", big.NewInt(7))
+	assert.Error(t, err)
+}
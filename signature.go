@@ -0,0 +1,260 @@
+package multicall
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// parsed signatures are immutable, so they're shared by every caller.
+var signatureCache sync.Map // string -> *parsedSignature
+
+type parsedSignature struct {
+	abi    abi.ABI
+	method abi.Method
+}
+
+// words that may appear in a Solidity-style signature but carry no type information.
+var signatureKeywords = map[string]bool{
+	"view": true, "pure": true, "external": true, "public": true, "payable": true, "nonpayable": true,
+	"returns": true, "memory": true, "calldata": true, "storage": true,
+}
+
+/*
+ * Parses a human-readable function signature into an abi.Method.
+ *
+ * Accepts the compact form used by most tooling as well as Solidity-style declarations, e.g.
+ *	- "balanceOf(address)(uint256)"
+ *	- "getReserves()(uint112,uint112,uint32)"
+ *	- "function balanceOf(address owner) view returns (uint256 balance)"
+ *	- "positions(uint256)((address token0, address token1), uint128)"
+ *
+ * Results are cached, so parsing the same signature repeatedly is cheap.
+ */
+func ParseSignature(signature string) (*abi.Method, error) {
+	parsed, err := parseSignatureCached(signature)
+	if err != nil {
+		return nil, err
+	}
+	method := parsed.method
+	return &method, nil
+}
+
+// Describes a call from a human-readable signature (see `ParseSignature`) instead of a full ABI.
+func DescribeSig[T any](contractAddress common.Address, signature string, params ...interface{}) (*MultiCallMetaData[T], error) {
+	parsed, err := parseSignatureCached(signature)
+	if err != nil {
+		return nil, err
+	}
+	outputs := parsed.method.Outputs
+	return DescribeWithDeserialize(
+		contractAddress,
+		parsed.abi,
+		func(b []byte) (*T, error) {
+			return unpackOutputs[T](outputs, b)
+		},
+		parsed.method.Name,
+		params...,
+	)
+}
+
+func parseSignatureCached(signature string) (*parsedSignature, error) {
+	if cached, ok := signatureCache.Load(signature); ok {
+		return cached.(*parsedSignature), nil
+	}
+
+	method, err := parseSignature(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature %q: %w", signature, err)
+	}
+	parsed := &parsedSignature{
+		abi:    abi.ABI{Methods: map[string]abi.Method{method.Name: method}},
+		method: method,
+	}
+	signatureCache.Store(signature, parsed)
+	return parsed, nil
+}
+
+func parseSignature(signature string) (abi.Method, error) {
+	sig := strings.TrimSpace(signature)
+	sig = strings.TrimSpace(strings.TrimPrefix(sig, "function "))
+
+	open := strings.Index(sig, "(")
+	if open <= 0 {
+		return abi.Method{}, fmt.Errorf("missing function name or parameter list")
+	}
+	name := strings.TrimSpace(sig[:open])
+	if strings.ContainsAny(name, " \t,)") {
+		return abi.Method{}, fmt.Errorf("invalid function name %q", name)
+	}
+
+	inputList, rest, err := takeParenGroup(sig[open:])
+	if err != nil {
+		return abi.Method{}, err
+	}
+	inputs, err := parseParamList(inputList)
+	if err != nil {
+		return abi.Method{}, err
+	}
+
+	// skip over modifiers such as `view` and `returns` until we find the output list (if any).
+	mutability := "view"
+	rest = strings.TrimSpace(rest)
+	for rest != "" && !strings.HasPrefix(rest, "(") {
+		word := rest
+		if end := strings.IndexAny(rest, " \t("); end >= 0 {
+			word = rest[:end]
+		}
+		if !signatureKeywords[word] {
+			return abi.Method{}, fmt.Errorf("unexpected %q", word)
+		}
+		if word == "pure" || word == "payable" || word == "nonpayable" {
+			mutability = word
+		}
+		rest = strings.TrimSpace(rest[len(word):])
+	}
+
+	var outputs abi.Arguments
+	if rest != "" {
+		outputList, trailing, err := takeParenGroup(rest)
+		if err != nil {
+			return abi.Method{}, err
+		}
+		if strings.TrimSpace(trailing) != "" {
+			return abi.Method{}, fmt.Errorf("unexpected %q after outputs", strings.TrimSpace(trailing))
+		}
+		if outputs, err = parseParamList(outputList); err != nil {
+			return abi.Method{}, err
+		}
+	}
+
+	return abi.NewMethod(name, name, abi.Function, mutability, false, mutability == "payable", inputs, outputs), nil
+}
+
+// takeParenGroup splits "(a,(b,c))rest" into "a,(b,c)" and "rest".
+func takeParenGroup(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return "", "", fmt.Errorf("expected '(' in %q", s)
+	}
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unbalanced parentheses in %q", s)
+}
+
+// splitTopLevel splits on commas that aren't nested inside parentheses.
+func splitTopLevel(s string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseParamList(list string) (abi.Arguments, error) {
+	args := abi.Arguments{}
+	if strings.TrimSpace(list) == "" {
+		return args, nil
+	}
+	for i, param := range splitTopLevel(list) {
+		marshaling, err := parseParam(param)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i, err)
+		}
+		typ, err := abi.NewType(marshaling.Type, "", marshaling.Components)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i, err)
+		}
+		args = append(args, abi.Argument{Name: marshaling.Name, Type: typ})
+	}
+	return args, nil
+}
+
+// parseParam parses a single `type [location] [name]` declaration, where type may be a (nested) tuple.
+func parseParam(param string) (abi.ArgumentMarshaling, error) {
+	param = strings.TrimSpace(param)
+	if param == "" {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("empty type")
+	}
+
+	var out abi.ArgumentMarshaling
+	var rest string
+	if strings.HasPrefix(param, "(") || strings.HasPrefix(param, "tuple(") {
+		inner, after, err := takeParenGroup(strings.TrimPrefix(param, "tuple"))
+		if err != nil {
+			return out, err
+		}
+		for i, component := range splitTopLevel(inner) {
+			parsed, err := parseParam(component)
+			if err != nil {
+				return out, err
+			}
+			if parsed.Name == "" {
+				// go-ethereum needs a name for every tuple component.
+				parsed.Name = fmt.Sprintf("field%d", i)
+			}
+			out.Components = append(out.Components, parsed)
+		}
+		// array suffixes such as `[]` or `[2][]` follow the closing parenthesis.
+		suffixEnd := strings.IndexAny(after, " \t")
+		if suffixEnd < 0 {
+			suffixEnd = len(after)
+		}
+		out.Type = "tuple" + after[:suffixEnd]
+		rest = after[suffixEnd:]
+	} else {
+		fields := strings.Fields(param)
+		out.Type = normalizeElementaryType(fields[0])
+		rest = strings.Join(fields[1:], " ")
+	}
+
+	for _, word := range strings.Fields(rest) {
+		if signatureKeywords[word] || word == "indexed" {
+			continue
+		}
+		if out.Name != "" {
+			return out, fmt.Errorf("unexpected %q", word)
+		}
+		out.Name = word
+	}
+	return out, nil
+}
+
+// normalizeElementaryType expands the aliases Solidity allows (uint -> uint256, int -> int256).
+func normalizeElementaryType(t string) string {
+	base, suffix := t, ""
+	if idx := strings.Index(t, "["); idx >= 0 {
+		base, suffix = t[:idx], t[idx:]
+	}
+	switch base {
+	case "uint", "int":
+		base += "256"
+	case "byte":
+		base = "bytes1"
+	}
+	return base + suffix
+}
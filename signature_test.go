@@ -0,0 +1,100 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSignature(t *testing.T) {
+	method, err := ParseSignature("balanceOf(address)(uint256)")
+	assert.NoError(t, err)
+	assert.Equal(t, "balanceOf", method.Name)
+	assert.Equal(t, "balanceOf(address)", method.Sig)
+	assert.Equal(t, common.FromHex("0x70a08231"), method.ID)
+	assert.Len(t, method.Outputs, 1)
+	assert.Equal(t, "uint256", method.Outputs[0].Type.String())
+
+	method, err = ParseSignature("function getReserves() external view returns (uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)")
+	assert.NoError(t, err)
+	assert.Equal(t, "getReserves()", method.Sig)
+	assert.Len(t, method.Outputs, 3)
+	assert.Equal(t, "blockTimestampLast", method.Outputs[2].Name)
+
+	method, err = ParseSignature("positions(uint)((address token0, address token1)[], bytes memory)")
+	assert.NoError(t, err)
+	assert.Equal(t, "positions(uint256)", method.Sig)
+	assert.Equal(t, "(address,address)[]", method.Outputs[0].Type.String())
+	assert.Equal(t, "bytes", method.Outputs[1].Type.String())
+
+	// signatures without outputs are fine, they just can't be deserialized into anything.
+	method, err = ParseSignature("transfer(address,uint256)")
+	assert.NoError(t, err)
+	assert.Len(t, method.Outputs, 0)
+
+	for _, bad := range []string{"", "balanceOf", "balanceOf(address", "balanceOf(address)(uint256) extra", "balanceOf(notatype)", "(uint256)"} {
+		_, err := ParseSignature(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestParseSignature_Cached(t *testing.T) {
+	first, err := parseSignatureCached("decimals()(uint8)")
+	assert.NoError(t, err)
+	second, err := parseSignatureCached("decimals()(uint8)")
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+}
+
+func TestDescribeSig(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0x20)
+	pair := fakeAddress(0x21)
+	chain.contracts[token] = fakeERC20("TKN", 18)
+	chain.contracts[pair] = abiContract(mustParseABI(t, `[{"type":"function","name":"getReserves","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"","type":"uint112"},{"name":"","type":"uint112"},{"name":"","type":"uint32"}]}]`), map[string]fakeMethod{
+		"getReserves": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(1000), big.NewInt(2000), uint32(1234)}, nil
+		},
+	})
+	mc := newFakeClient(t, chain)
+
+	owner := fakeAddress(0x1234)
+	balanceCall, err := DescribeSig[big.Int](token, "balanceOf(address)(uint256)", owner)
+	assert.NoError(t, err)
+
+	type reserves struct {
+		Reserve0           *big.Int
+		Reserve1           *big.Int
+		BlockTimestampLast uint32
+	}
+	reservesCall, err := DescribeSig[reserves](pair, "getReserves()(uint112,uint112,uint32)")
+	assert.NoError(t, err)
+
+	balance, res, err := Do(mc, balanceCall, reservesCall)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeBalance(owner), balance)
+	assert.Equal(t, big.NewInt(1000), res.Reserve0)
+	assert.Equal(t, big.NewInt(2000), res.Reserve1)
+	assert.Equal(t, uint32(1234), res.BlockTimestampLast)
+
+	// raw values are available too.
+	rawCall, err := DescribeSig[[]interface{}](pair, "getReserves()(uint112,uint112,uint32)")
+	assert.NoError(t, err)
+	raw, err := DoMany(mc, rawCall)
+	assert.NoError(t, err)
+	assert.Len(t, *(*raw)[0], 3)
+
+	// bad arguments are reported when describing the call.
+	_, err = DescribeSig[big.Int](token, "balanceOf(address)(uint256)", "not an address")
+	assert.Error(t, err)
+
+	// mismatched types surface as a failed call instead of a panic.
+	wrongType, err := DescribeSig[string](token, "balanceOf(address)(uint256)", owner)
+	assert.NoError(t, err)
+	results, err := DoManyAllowFailures(mc, wrongType)
+	assert.NoError(t, err)
+	assert.False(t, (*results)[0].Success)
+}
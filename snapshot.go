@@ -0,0 +1,173 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The parameterless view state of a contract at one block.
+type Snapshot struct {
+	Address common.Address
+	Block   *big.Int
+	// Method name -> value. Methods with several outputs map to a `map[string]interface{}` of their outputs.
+	Values map[string]interface{}
+	// Methods that reverted.
+	Errors map[string]error
+}
+
+// A method whose value differs between two snapshots. A side is nil when the method is missing from it, and
+// holds the error when the method reverted.
+type SnapshotChange struct {
+	Name   string
+	Before interface{}
+	After  interface{}
+}
+
+/*
+ * Reads every view or pure method of the ABI that takes no arguments (`Line()`, `debt()`, `live()`...) in a
+ * single multicall, at the latest block.
+ */
+func SnapshotContract(mc *MulticallClient, contractAddress common.Address, contractAbi abi.ABI) (*Snapshot, error) {
+	return SnapshotContractWithOptions(mc, nil, contractAddress, contractAbi)
+}
+
+func SnapshotContractWithOptions(mc *MulticallClient, options *bind.CallOpts, contractAddress common.Address, contractAbi abi.ABI) (*Snapshot, error) {
+	calls := []DynamicCall{}
+	customErrors := abiErrors(contractAbi)
+	for _, method := range contractAbi.Methods {
+		if method.IsConstant() && len(method.Inputs) == 0 && len(method.Outputs) > 0 {
+			calls = append(calls, DynamicCall{Address: contractAddress, Method: method, Errors: customErrors})
+		}
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Method.Name < calls[j].Method.Name })
+
+	pinned, err := mc.pinBlockNumber(mc.callOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	results, err := DoManyDynamicWithOptions(mc, pinned, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Address: contractAddress,
+		Block:   pinned.BlockNumber,
+		Values:  map[string]interface{}{},
+		Errors:  map[string]error{},
+	}
+	for i, res := range results {
+		name := calls[i].Method.Name
+		switch {
+		case res.Err != nil:
+			snapshot.Errors[name] = res.Err
+		case len(res.Outputs) == 1:
+			snapshot.Values[name] = res.Outputs[0].Value
+		default:
+			snapshot.Values[name] = res.Map()
+		}
+	}
+	return snapshot, nil
+}
+
+// Lists the methods whose values differ from `before`, by name.
+func (s *Snapshot) Diff(before *Snapshot) []SnapshotChange {
+	changes := []SnapshotChange{}
+	for _, name := range unionNames(before, s) {
+		was, now := before.get(name), s.get(name)
+		if reflect.DeepEqual(was, now) {
+			continue
+		}
+		if wasErr, ok := was.(error); ok {
+			if nowErr, ok := now.(error); ok && wasErr.Error() == nowErr.Error() {
+				continue
+			}
+		}
+		changes = append(changes, SnapshotChange{Name: name, Before: was, After: now})
+	}
+	return changes
+}
+
+func (s *Snapshot) get(name string) interface{} {
+	if err, ok := s.Errors[name]; ok {
+		return err
+	}
+	return s.Values[name]
+}
+
+func unionNames(snapshots ...*Snapshot) []string {
+	seen := map[string]bool{}
+	for _, s := range snapshots {
+		for name := range s.Values {
+			seen[name] = true
+		}
+		for name := range s.Errors {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Prints one `name = value` line per method, sorted by name.
+func (s *Snapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s", s.Address.Hex())
+	if s.Block != nil {
+		fmt.Fprintf(&b, " @ block %s", s.Block)
+	}
+	b.WriteString("\n")
+	for _, name := range unionNames(s) {
+		fmt.Fprintf(&b, "  %s = %s\n", name, formatSnapshotValue(s.get(name)))
+	}
+	return b.String()
+}
+
+func (c SnapshotChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Name, formatSnapshotValue(c.Before), formatSnapshotValue(c.After))
+}
+
+func formatSnapshotValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "<missing>"
+	case error:
+		return fmt.Sprintf("<%s>", v.Error())
+	case *big.Int:
+		return v.String()
+	case common.Address:
+		return v.Hex()
+	case []byte:
+		return hexutil.Encode(v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = fmt.Sprintf("%s: %s", key, formatSnapshotValue(v[key]))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return hexutil.Encode(b)
+	}
+	return fmt.Sprint(value)
+}
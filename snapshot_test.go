@@ -0,0 +1,46 @@
+package multicall
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotContract(t *testing.T) {
+	chain := newFakeChain()
+	vat := fakeAddress(0x7a)
+	chain.contracts[vat] = fakeVat(t)
+	mc := newFakeClient(t, chain)
+	raw, err := os.ReadFile("abi.json")
+	assert.NoError(t, err)
+	vatAbi := mustParseABI(t, string(raw))
+
+	before, err := SnapshotContract(mc, vat, vatAbi)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), before.Block)
+	// only the parameterless views of the Vat are read.
+	assert.Equal(t, map[string]interface{}{
+		"Line": big.NewInt(1e9),
+		"debt": big.NewInt(100_000),
+		"live": big.NewInt(1),
+	}, before.Values)
+	assert.Contains(t, before.Errors, "vice")
+	assert.Len(t, before.Errors, 1)
+
+	assert.Equal(t, vat.Hex()+` @ block 100
+  Line = 1000000000
+  debt = 100000
+  live = 1
+  vice = <call failed>
+`, before.String())
+
+	chain.blockNumber = 105
+	after, err := SnapshotContract(mc, vat, vatAbi)
+	assert.NoError(t, err)
+	changes := after.Diff(before)
+	assert.Equal(t, []SnapshotChange{{Name: "debt", Before: big.NewInt(100_000), After: big.NewInt(105_000)}}, changes)
+	assert.Equal(t, "debt: 100000 -> 105000", changes[0].String())
+	assert.Empty(t, after.Diff(after))
+}
@@ -0,0 +1,480 @@
+package multicall
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+ * A batch of reads described in YAML (or JSON), so new on-chain metrics don't need any Go:
+ *
+ *	contracts:
+ *	  vat:
+ *	    address: "0x35D1b3F3D7966A1DFe207aa4514C12a259A0492B"
+ *	    abi: abis/vat.json
+ *	vars:
+ *	  ilk: "0x4554482d41000000000000000000000000000000000000000000000000000000"
+ *	  wallets: ["0x…", "0x…"]
+ *	calls:
+ *	  - name: eth-a
+ *	    contract: vat
+ *	    method: ilks
+ *	    args: ["{{ilk}}"]
+ *	    outputs: {debt: Art, rate: rate}
+ *	  - name: dai
+ *	    contract: vat
+ *	    signature: dai(address)(uint256)
+ *	    forEach: wallets
+ *	    args: ["{{item}}"]
+ *
+ * `{{name}}` in addresses and arguments is replaced by a var; a template that makes up a whole value is replaced
+ * by the var itself, so lists can be passed as arguments. `forEach` repeats a call for every element of a list
+ * var, bound to `{{item}}` (or to the name given by `as`). `outputs` picks and renames outputs; by default every
+ * output is kept under its ABI name.
+ */
+type Spec struct {
+	Contracts map[string]SpecContract `yaml:"contracts" json:"contracts"`
+	Vars      map[string]interface{}  `yaml:"vars" json:"vars"`
+	Calls     []SpecCall              `yaml:"calls" json:"calls"`
+}
+
+type SpecContract struct {
+	Address string `yaml:"address" json:"address"`
+	// Path to a JSON ABI (or a build artifact with an `abi` key), relative to the spec file.
+	ABI string `yaml:"abi" json:"abi"`
+
+	parsed *abi.ABI
+}
+
+type SpecCall struct {
+	Name     string `yaml:"name" json:"name"`
+	Contract string `yaml:"contract" json:"contract"`
+	// Overrides the contract's address, e.g. to fan out over a list of tokens sharing an ABI.
+	Address string `yaml:"address" json:"address"`
+	// Either a method of the contract's ABI, or a human-readable signature.
+	Method    string        `yaml:"method" json:"method"`
+	Signature string        `yaml:"signature" json:"signature"`
+	Args      []interface{} `yaml:"args" json:"args"`
+	ForEach   string        `yaml:"forEach" json:"forEach"`
+	As        string        `yaml:"as" json:"as"`
+	// Field name -> output name.
+	Outputs map[string]string `yaml:"outputs" json:"outputs"`
+}
+
+// One executed call of a spec. Calls using `forEach` produce a row per item.
+type SpecRow struct {
+	Call    string
+	Item    interface{}
+	Target  common.Address
+	Outputs []NamedValue
+	Err     error
+}
+
+// Encodes the row with `MarshalValueJSON()`, as `{"call", "item", "target", "outputs"}` or `{..., "error"}`.
+func (r SpecRow) MarshalJSON() ([]byte, error) {
+	outputs := jsonObject{}
+	for _, output := range r.Outputs {
+		encoded, err := MarshalValueJSON(output.Type, output.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", r.Call, output.Name, err)
+		}
+		outputs.keys = append(outputs.keys, output.Name)
+		outputs.values = append(outputs.values, json.RawMessage(encoded))
+	}
+	row := struct {
+		Call    string         `json:"call"`
+		Item    interface{}    `json:"item,omitempty"`
+		Target  common.Address `json:"target"`
+		Outputs *jsonObject    `json:"outputs,omitempty"`
+		Error   string         `json:"error,omitempty"`
+	}{Call: r.Call, Item: r.Item, Target: r.Target}
+	if r.Err != nil {
+		row.Error = r.Err.Error()
+	} else {
+		row.Outputs = &outputs
+	}
+	return json.Marshal(row)
+}
+
+// Reads a spec file, and the ABIs it refers to.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseSpec(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parses a spec, resolving ABI paths against `dir`.
+func ParseSpec(data []byte, dir string) (*Spec, error) {
+	spec := &Spec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+
+	for name, contract := range spec.Contracts {
+		if contract.ABI == "" {
+			continue
+		}
+		path := contract.ABI
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		parsed, err := loadABIFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("contract %s: %w", name, err)
+		}
+		contract.parsed = parsed
+		spec.Contracts[name] = contract
+	}
+
+	names := map[string]bool{}
+	for i, call := range spec.Calls {
+		if call.Name == "" {
+			return nil, fmt.Errorf("call %d has no name", i)
+		}
+		if names[call.Name] {
+			return nil, fmt.Errorf("call %s is defined twice", call.Name)
+		}
+		names[call.Name] = true
+		if _, err := spec.method(call); err != nil {
+			return nil, fmt.Errorf("call %s: %w", call.Name, err)
+		}
+	}
+	return spec, nil
+}
+
+func loadABIFile(path string) (*abi.ABI, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// build artifacts (hardhat, foundry) keep the ABI under an `abi` key.
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if json.Unmarshal(data, &artifact) == nil && len(artifact.ABI) > 0 {
+		data = artifact.ABI
+	}
+	parsed, err := abi.JSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid abi %s: %w", path, err)
+	}
+	return &parsed, nil
+}
+
+// Runs every call of the spec in one batch. `vars` add to (and override) the spec's own vars.
+func RunSpec(mc *MulticallClient, spec *Spec, vars map[string]interface{}) ([]SpecRow, error) {
+	return RunSpecWithOptions(mc, nil, spec, vars)
+}
+
+func RunSpecWithOptions(mc *MulticallClient, options *bind.CallOpts, spec *Spec, vars map[string]interface{}) ([]SpecRow, error) {
+	scope := map[string]interface{}{}
+	for name, value := range spec.Vars {
+		scope[name] = value
+	}
+	for name, value := range vars {
+		scope[name] = value
+	}
+
+	rows := []SpecRow{}
+	calls := []DynamicCall{}
+	sources := []SpecCall{}
+	for _, call := range spec.Calls {
+		items := []interface{}{nil}
+		if call.ForEach != "" {
+			list, ok := listVar(scope[call.ForEach])
+			if !ok {
+				return nil, fmt.Errorf("call %s: forEach needs a list var, but %q is %T", call.Name, call.ForEach, scope[call.ForEach])
+			}
+			items = list
+		}
+
+		for _, item := range items {
+			callScope := scope
+			if call.ForEach != "" {
+				callScope = map[string]interface{}{}
+				for name, value := range scope {
+					callScope[name] = value
+				}
+				callScope[call.itemName()] = item
+			}
+			dynamic, err := spec.compile(call, callScope)
+			if err != nil {
+				if item != nil {
+					return nil, fmt.Errorf("call %s (%v): %w", call.Name, item, err)
+				}
+				return nil, fmt.Errorf("call %s: %w", call.Name, err)
+			}
+			rows = append(rows, SpecRow{Call: call.Name, Item: item, Target: dynamic.Address})
+			calls = append(calls, dynamic)
+			sources = append(sources, call)
+		}
+	}
+	if len(calls) == 0 {
+		return rows, nil
+	}
+
+	results, err := DoManyDynamicWithOptions(mc, options, calls)
+	if err != nil {
+		return nil, err
+	}
+	for i, res := range results {
+		if res.Err != nil {
+			rows[i].Err = res.Err
+			continue
+		}
+		rows[i].Outputs = sources[i].selectOutputs(res.Outputs)
+	}
+	return rows, nil
+}
+
+func (call SpecCall) itemName() string {
+	if call.As != "" {
+		return call.As
+	}
+	return "item"
+}
+
+// selectOutputs renames outputs per `call.Outputs`, keeping them in ABI order.
+func (call SpecCall) selectOutputs(outputs []NamedValue) []NamedValue {
+	if len(call.Outputs) == 0 {
+		return outputs
+	}
+	selected := []NamedValue{}
+	for _, output := range outputs {
+		fields := []string{}
+		for field, name := range call.Outputs {
+			if name == output.Name {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			selected = append(selected, NamedValue{Name: field, Type: output.Type, Value: output.Value})
+		}
+	}
+	return selected
+}
+
+func (spec *Spec) method(call SpecCall) (abi.Method, error) {
+	var method abi.Method
+	switch {
+	case call.Signature != "":
+		parsed, err := parseSignatureCached(call.Signature)
+		if err != nil {
+			return abi.Method{}, err
+		}
+		method = parsed.method
+	case call.Method != "":
+		contract, ok := spec.Contracts[call.Contract]
+		if !ok {
+			return abi.Method{}, fmt.Errorf("unknown contract %q", call.Contract)
+		}
+		if contract.parsed == nil {
+			return abi.Method{}, fmt.Errorf("contract %q has no abi to look up %q in", call.Contract, call.Method)
+		}
+		if method, ok = contract.parsed.Methods[call.Method]; !ok {
+			return abi.Method{}, fmt.Errorf("method %q not found in the abi of %q", call.Method, call.Contract)
+		}
+	default:
+		return abi.Method{}, fmt.Errorf("call needs either a method or a signature")
+	}
+
+	if len(call.Args) != len(method.Inputs) {
+		return abi.Method{}, fmt.Errorf("%s takes %d arguments, got %d", method.Name, len(method.Inputs), len(call.Args))
+	}
+	for field, name := range call.Outputs {
+		found := false
+		for i, output := range method.Outputs {
+			found = found || argumentKey(output.Name, i) == name
+		}
+		if !found {
+			return abi.Method{}, fmt.Errorf("output %q (for field %q) not found in %s", name, field, method.Name)
+		}
+	}
+	return method, nil
+}
+
+func (spec *Spec) compile(call SpecCall, scope map[string]interface{}) (DynamicCall, error) {
+	method, err := spec.method(call)
+	if err != nil {
+		return DynamicCall{}, err
+	}
+
+	address := call.Address
+	var customErrors []abi.Error
+	if contract, ok := spec.Contracts[call.Contract]; ok {
+		if address == "" {
+			address = contract.Address
+		}
+		if contract.parsed != nil {
+			customErrors = abiErrors(*contract.parsed)
+		}
+	}
+	expandedAddress, err := expandTemplate(address, scope)
+	if err != nil {
+		return DynamicCall{}, err
+	}
+	target, ok := addressVar(expandedAddress)
+	if !ok {
+		return DynamicCall{}, fmt.Errorf("invalid address %v", expandedAddress)
+	}
+
+	args := make([]interface{}, len(call.Args))
+	for i, arg := range call.Args {
+		expanded, err := expandTemplate(arg, scope)
+		if err != nil {
+			return DynamicCall{}, err
+		}
+		// arguments are typed by going through the ABI's JSON codec.
+		encoded, err := json.Marshal(hexBytes(expanded))
+		if err != nil {
+			return DynamicCall{}, err
+		}
+		decoded, err := decodeABIValue(method.Inputs[i].Type, encoded)
+		if err != nil {
+			return DynamicCall{}, fmt.Errorf("argument %s: %w", argumentKey(method.Inputs[i].Name, i), err)
+		}
+		args[i] = decoded.Interface()
+	}
+
+	return DynamicCall{Address: target, Method: method, Args: args, Errors: customErrors}, nil
+}
+
+var templatePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// listVar returns the elements of a list var, which may be decoded from YAML or be any Go slice or array.
+func listVar(value interface{}) ([]interface{}, bool) {
+	if list, ok := value.([]interface{}); ok {
+		return list, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	// byte slices are values (e.g. bytes32), not lists.
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list, true
+}
+
+// addressVar returns the address a var holds, either as a hex string or as a Go value like `common.Address`.
+func addressVar(value interface{}) (common.Address, bool) {
+	switch v := value.(type) {
+	case common.Address:
+		return v, true
+	case *common.Address:
+		if v == nil {
+			return common.Address{}, false
+		}
+		return *v, true
+	case string:
+		return common.HexToAddress(v), common.IsHexAddress(v)
+	case fmt.Stringer:
+		return addressVar(v.String())
+	}
+	return common.Address{}, false
+}
+
+// hexBytes hex-encodes the Go byte slices and arrays in a value, which `json.Marshal()` would write as base64 or
+// as lists of numbers.
+func hexBytes(value interface{}) interface{} {
+	switch value.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return value
+	case map[string]interface{}:
+		out := map[string]interface{}{}
+		for key, elem := range value.(map[string]interface{}) {
+			out[key] = hexBytes(elem)
+		}
+		return out
+	}
+	v := reflect.ValueOf(value)
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, v.Len())
+		for i := range b {
+			b[i] = byte(v.Index(i).Uint())
+		}
+		return hexutil.Encode(b)
+	}
+	if list, ok := listVar(value); ok {
+		out := make([]interface{}, len(list))
+		for i, elem := range list {
+			out[i] = hexBytes(elem)
+		}
+		return out
+	}
+	return value
+}
+
+// expandTemplate substitutes `{{name}}` in strings, recursing into lists and maps.
+func expandTemplate(value interface{}, scope map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if match := templatePattern.FindStringSubmatch(v); match != nil && match[0] == strings.TrimSpace(v) {
+			resolved, ok := scope[match[1]]
+			if !ok {
+				return nil, fmt.Errorf("unknown var %q", match[1])
+			}
+			return resolved, nil
+		}
+		var missing error
+		expanded := templatePattern.ReplaceAllStringFunc(v, func(template string) string {
+			name := templatePattern.FindStringSubmatch(template)[1]
+			resolved, ok := scope[name]
+			if !ok {
+				missing = fmt.Errorf("unknown var %q", name)
+				return template
+			}
+			return fmt.Sprint(resolved)
+		})
+		return expanded, missing
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			expanded, err := expandTemplate(elem, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			expanded, err := expandTemplate(elem, scope)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = expanded
+		}
+		return out, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,196 @@
+package multicall
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVat serves `ilks`, `Line`, `debt` and `live` from the Maker Vat ABI in abi.json. Its debt grows with the
+// block number.
+func fakeVat(t *testing.T) fakeContract {
+	raw, err := os.ReadFile("abi.json")
+	assert.NoError(t, err)
+	return abiContract(mustParseABI(t, string(raw)), map[string]fakeMethod{
+		"ilks": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			ilk := args[0].([32]byte)
+			return []interface{}{big.NewInt(int64(ilk[0])), big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5)}, nil
+		},
+		"Line": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(1e9)}, nil
+		},
+		"debt": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{new(big.Int).SetUint64(ctx.block * 1000)}, nil
+		},
+		"live": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(1)}, nil
+		},
+	})
+}
+
+func TestSpec(t *testing.T) {
+	chain := newFakeChain()
+	vat := fakeAddress(0x7a)
+	tokenA, tokenB := fakeAddress(0xa0), fakeAddress(0xb0)
+	chain.contracts[vat] = fakeVat(t)
+	chain.contracts[tokenA] = fakeERC20("A", 18)
+	chain.contracts[tokenB] = fakeERC20("B", 6)
+	mc := newFakeClient(t, chain)
+
+	vatAbi, err := filepath.Abs("abi.json")
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "abis"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "abis", "erc20.json"), []byte(`{"abi": `+erc20TestAbi+`}`), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(`
+contracts:
+  vat:
+    address: "{{vat}}"
+    abi: `+vatAbi+`
+  erc20:
+    abi: abis/erc20.json
+vars:
+  ilk: "0x0900000000000000000000000000000000000000000000000000000000000000"
+  tokens: ["`+tokenA.Hex()+`", "`+tokenB.Hex()+`"]
+calls:
+  - name: eth-a
+    contract: vat
+    method: ilks
+    args: ["{{ilk}}"]
+    outputs: {debt: Art, rate: rate}
+  - name: ceiling
+    contract: vat
+    method: Line
+  - name: balances
+    contract: erc20
+    address: "{{token}}"
+    method: balanceOf
+    forEach: tokens
+    as: token
+    args: ["{{holder}}"]
+  - name: symbols
+    signature: symbol()(string)
+    address: "{{item}}"
+    forEach: tokens
+`), 0o644))
+
+	spec, err := LoadSpec(filepath.Join(dir, "spec.yaml"))
+	assert.NoError(t, err)
+
+	holder := fakeAddress(0x0102)
+	rows, err := RunSpec(mc, spec, map[string]interface{}{"vat": vat.Hex(), "holder": holder.Hex()})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 6)
+
+	assert.Equal(t, "eth-a", rows[0].Call)
+	assert.Equal(t, []NamedValue{
+		{Name: "debt", Type: rows[0].Outputs[0].Type, Value: big.NewInt(9)},
+		{Name: "rate", Type: rows[0].Outputs[1].Type, Value: big.NewInt(2)},
+	}, rows[0].Outputs)
+	assert.Equal(t, big.NewInt(1e9), rows[1].Outputs[0].Value)
+
+	assert.Equal(t, tokenA, rows[2].Target)
+	assert.Equal(t, tokenA.Hex(), rows[2].Item)
+	assert.Equal(t, fakeBalance(holder), rows[2].Outputs[0].Value)
+	assert.Equal(t, tokenB, rows[3].Target)
+	assert.Equal(t, "A", rows[4].Outputs[0].Value)
+	assert.Equal(t, "B", rows[5].Outputs[0].Value)
+
+	encoded, err := json.Marshal(rows[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"call":"eth-a","target":"`+vat.Hex()+`","outputs":{"debt":"9","rate":"2"}}`, string(encoded))
+
+	_, err = RunSpec(mc, spec, nil)
+	assert.ErrorContains(t, err, `unknown var "vat"`)
+}
+
+func TestParseSpec_Validates(t *testing.T) {
+	for name, raw := range map[string]string{
+		"unknown key":      "calls:\n  - name: a\n    signature: symbol()(string)\n    targte: x\n",
+		"missing name":     "calls:\n  - signature: symbol()(string)\n",
+		"duplicate name":   "calls:\n  - name: a\n    signature: symbol()(string)\n  - name: a\n    signature: symbol()(string)\n",
+		"unknown contract": "calls:\n  - name: a\n    contract: nope\n    method: symbol\n",
+		"wrong arg count":  "calls:\n  - name: a\n    signature: balanceOf(address)(uint256)\n",
+		"unknown output":   "calls:\n  - name: a\n    signature: symbol()(string)\n    outputs: {s: sym}\n",
+	} {
+		_, err := ParseSpec([]byte(raw), ".")
+		assert.Error(t, err, name)
+	}
+
+	spec, err := ParseSpec([]byte(`{"calls": [{"name": "a", "signature": "symbol()(string)", "address": "`+common.Address{1}.Hex()+`"}]}`), ".")
+	assert.NoError(t, err)
+	assert.Len(t, spec.Calls, 1)
+}
+
+func TestSpec_GoTypedVars(t *testing.T) {
+	chain := newFakeChain()
+	tokenA, tokenB := fakeAddress(0xa0), fakeAddress(0xb0)
+	chain.contracts[tokenA] = fakeERC20("A", 18)
+	chain.contracts[tokenB] = fakeERC20("B", 6)
+	mc := newFakeClient(t, chain)
+
+	spec, err := ParseSpec([]byte(`
+calls:
+  - name: balances
+    signature: balanceOf(address)(uint256)
+    address: "{{token}}"
+    forEach: tokens
+    as: token
+    args: ["{{holder}}"]
+  - name: symbol
+    signature: symbol()(string)
+    address: "{{main}}"
+`), ".")
+	assert.NoError(t, err)
+
+	holder := fakeAddress(0x0102)
+	for name, tokens := range map[string]interface{}{
+		"addresses": []common.Address{tokenA, tokenB},
+		"strings":   []string{tokenA.Hex(), tokenB.Hex()},
+		"array":     [2]*common.Address{&tokenA, &tokenB},
+	} {
+		rows, err := RunSpec(mc, spec, map[string]interface{}{"tokens": tokens, "holder": holder, "main": tokenB})
+		assert.NoError(t, err, name)
+		assert.Len(t, rows, 3, name)
+		assert.Equal(t, tokenA, rows[0].Target, name)
+		assert.Equal(t, tokenB, rows[1].Target, name)
+		assert.Equal(t, fakeBalance(holder), rows[0].Outputs[0].Value, name)
+		assert.Equal(t, "B", rows[2].Outputs[0].Value, name)
+	}
+
+	_, err = RunSpec(mc, spec, map[string]interface{}{"tokens": tokenA, "holder": holder, "main": tokenB})
+	assert.ErrorContains(t, err, "forEach needs a list var")
+
+	// byte slices and arrays are passed as hex, like they'd be written in YAML.
+	digester := fakeAddress(0xd0)
+	digestAbi := mustParseABI(t, `[{"type":"function","name":"digest","stateMutability":"view",
+		"inputs":[{"name":"key","type":"bytes32"},{"name":"data","type":"bytes"},{"name":"tags","type":"bytes4[]"}],
+		"outputs":[{"name":"","type":"uint256"}]}]`)
+	chain.contracts[digester] = abiContract(digestAbi, map[string]fakeMethod{
+		"digest": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			key, data, tags := args[0].([32]byte), args[1].([]byte), args[2].([][4]byte)
+			return []interface{}{big.NewInt(int64(key[0])*10000 + int64(len(data))*100 + int64(tags[1][3]))}, nil
+		},
+	})
+	spec, err = ParseSpec([]byte(`
+calls:
+  - name: digest
+    signature: digest(bytes32,bytes,bytes4[])(uint256)
+    address: "{{digester}}"
+    args: ["{{key}}", "{{data}}", "{{tags}}"]
+`), ".")
+	assert.NoError(t, err)
+	rows, err := RunSpec(mc, spec, map[string]interface{}{
+		"digester": digester,
+		"key":      [32]byte{7},
+		"data":     []byte{1, 2, 3},
+		"tags":     [][4]byte{{0, 0, 0, 1}, {0, 0, 0, 2}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(70302), rows[0].Outputs[0].Value)
+}
@@ -0,0 +1,116 @@
+package multicall
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// A streamed result, tagged with the position of its call in the input.
+type IndexedResult[A any] struct {
+	Index int
+	Result[A]
+}
+
+/*
+ * Executes calls as they're produced, without materializing the whole set in memory.
+ *
+ * `calls` is pulled one call at a time until a chunk of MaxBatchSize bytes is full; that chunk is executed and
+ * its results are handed to the consumer before the next call is pulled. At most one chunk of calls and results
+ * is held at once, and a slow consumer slows down the producer.
+ *
+ * Both the input and the returned function have the shape of Go 1.23's `iter.Seq` / `iter.Seq2`, so on newer
+ * toolchains you can pass an iterator in and `range` over the output:
+ *
+ *	for i, res := range multicall.StreamMany(mc, nil, holderCalls) { ... }
+ *
+ * If a chunk can't be executed at all (e.g. the RPC call fails), each of its calls yields a failed result
+ * carrying that error and the stream moves on to the next chunk. Stopping the iteration early stops pulling calls.
+ * Once the context (from `options`, or the client's) is cancelled, no more calls are pulled and no more chunks are
+ * executed: the calls already pulled yield failed results carrying the context's error.
+ */
+func StreamMany[A any](mc *MulticallClient, options *bind.CallOpts, calls func(yield func(*MultiCallMetaData[A]) bool)) func(yield func(int, Result[A]) bool) {
+	return func(yield func(int, Result[A]) bool) {
+		ctx := callContext(mc, options)
+		batch := []*MultiCallMetaData[A]{}
+		batchSize := 0
+		index := 0
+		stopped := false
+
+		flush := func() bool {
+			var results []Result[A]
+			err := ctx.Err()
+			if err == nil {
+				results, err = DoManyResultsWithOptions(mc, options, batch...)
+			}
+			for i := range batch {
+				res := failedResult[A](err)
+				if err == nil {
+					res = results[i]
+				}
+				if !yield(index, res) {
+					stopped = true
+					return false
+				}
+				index++
+			}
+			clear(batch)
+			batch = batch[:0]
+			batchSize = 0
+			return true
+		}
+
+		calls(func(call *MultiCallMetaData[A]) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			if len(batch) > 0 && exceedsBatchSize(batchSize, len(call.Data), int(mc.MaxBatchSize)) {
+				if !flush() {
+					return false
+				}
+			}
+			batch = append(batch, call)
+			batchSize += len(call.Data)
+			return true
+		})
+
+		if !stopped && len(batch) > 0 {
+			flush()
+		}
+	}
+}
+
+/*
+ * Channel flavor of `StreamMany()`. Calls are read from `calls` until it's closed, and results are sent on the
+ * returned channel, which is closed once every result has been delivered.
+ *
+ * Cancelling the context (from `options`, or the client's) stops the stream; otherwise the consumer must drain
+ * the output channel.
+ */
+func StreamManyChan[A any](mc *MulticallClient, options *bind.CallOpts, calls <-chan *MultiCallMetaData[A]) <-chan IndexedResult[A] {
+	ctx := callContext(mc, options)
+
+	out := make(chan IndexedResult[A])
+	go func() {
+		defer close(out)
+		input := func(yield func(*MultiCallMetaData[A]) bool) {
+			for {
+				select {
+				case call, ok := <-calls:
+					if !ok || !yield(call) {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		StreamMany(mc, options, input)(func(index int, res Result[A]) bool {
+			select {
+			case out <- IndexedResult[A]{Index: index, Result: res}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return out
+}
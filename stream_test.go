@@ -0,0 +1,165 @@
+package multicall
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/assert"
+)
+
+func holderCalls(t *testing.T, n int) func(yield func(*MultiCallMetaData[big.Int]) bool) {
+	token := fakeAddress(0x20)
+	return func(yield func(*MultiCallMetaData[big.Int]) bool) {
+		for i := 0; i < n; i++ {
+			call, err := DescribeSig[big.Int](token, "balanceOf(address)(uint256)", fakeAddress(1000+i))
+			assert.NoError(t, err)
+			if !yield(call) {
+				return
+			}
+		}
+	}
+}
+
+func TestStreamMany(t *testing.T) {
+	chain := newFakeChain()
+	chain.contracts[fakeAddress(0x20)] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+	mc.MaxBatchSize = 36 * 10 // 10 balanceOf calls per chunk
+
+	seen := 0
+	StreamMany(mc, nil, holderCalls(t, 45))(func(i int, res Result[big.Int]) bool {
+		assert.Equal(t, seen, i)
+		assert.True(t, res.Ok())
+		assert.Equal(t, *fakeBalance(fakeAddress(1000 + i)), res.Value)
+		seen++
+		return true
+	})
+	assert.Equal(t, 45, seen)
+	assert.Equal(t, 5, chain.ethCalls)
+
+	// stopping early stops pulling (and executing) further chunks.
+	chain.ethCalls = 0
+	seen = 0
+	StreamMany(mc, nil, holderCalls(t, 45))(func(i int, res Result[big.Int]) bool {
+		seen++
+		return i < 12
+	})
+	assert.Equal(t, 13, seen)
+	assert.Equal(t, 2, chain.ethCalls)
+}
+
+func TestStreamManyChan(t *testing.T) {
+	chain := newFakeChain()
+	chain.contracts[fakeAddress(0x20)] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+	mc.MaxBatchSize = 36 * 4
+
+	in := make(chan *MultiCallMetaData[big.Int])
+	go func() {
+		defer close(in)
+		holderCalls(t, 10)(func(call *MultiCallMetaData[big.Int]) bool {
+			in <- call
+			return true
+		})
+	}()
+
+	count := 0
+	for res := range StreamManyChan(mc, nil, in) {
+		assert.Equal(t, count, res.Index)
+		assert.Equal(t, *fakeBalance(fakeAddress(1000 + res.Index)), res.Value)
+		count++
+	}
+	assert.Equal(t, 10, count)
+
+	// a cancelled context closes the output without draining the input.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	never := make(chan *MultiCallMetaData[big.Int])
+	for range StreamManyChan(mc, &bind.CallOpts{Context: ctx}, never) {
+		t.Fatal("no results expected")
+	}
+}
+
+func TestStreamManyChanCancelled(t *testing.T) {
+	chain := newFakeChain()
+	chain.contracts[fakeAddress(0x20)] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+
+	// calls are already waiting when the context is cancelled, and may still be pulled into a batch.
+	in := make(chan *MultiCallMetaData[big.Int], 3)
+	holderCalls(t, 3)(func(call *MultiCallMetaData[big.Int]) bool {
+		in <- call
+		return true
+	})
+	close(in)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	chain.ethCalls = 0
+	for res := range StreamManyChan(mc, &bind.CallOpts{Context: ctx}, in) {
+		assert.ErrorIs(t, res.Err(), context.Canceled)
+	}
+	assert.Equal(t, 0, chain.ethCalls)
+
+	// the iterator isn't drained either: it stops at the first call.
+	pulled := 0
+	calls := func(yield func(*MultiCallMetaData[big.Int]) bool) {
+		holderCalls(t, 100)(func(call *MultiCallMetaData[big.Int]) bool {
+			pulled++
+			return yield(call)
+		})
+	}
+	results := 0
+	StreamMany(mc, &bind.CallOpts{Context: ctx}, calls)(func(i int, res Result[big.Int]) bool {
+		results++
+		return true
+	})
+	assert.Equal(t, 0, results)
+	assert.Equal(t, 1, pulled)
+	assert.Equal(t, 0, chain.ethCalls)
+}
+
+func TestStreamManyCancelledInFlight(t *testing.T) {
+	chain := newFakeChain()
+	chain.contracts[fakeAddress(0x20)] = fakeERC20("TKN", 18)
+	mc := newFakeClient(t, chain)
+	mc.MaxBatchSize = 36 * 10
+
+	// the first chunk cancels the context while it's being executed, and only returns once the test is over.
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	chain.contracts[fakeAddress(1000)] = func(ctx fakeCallContext, data []byte) ([]byte, error) {
+		cancel()
+		<-release
+		return nil, nil
+	}
+
+	pulled := 0
+	calls := func(yield func(*MultiCallMetaData[big.Int]) bool) {
+		for i := 0; i < 100; i++ {
+			call, err := DescribeSig[big.Int](fakeAddress(0x20), "balanceOf(address)(uint256)", fakeAddress(1000+i))
+			assert.NoError(t, err)
+			if i == 0 {
+				call.Address = fakeAddress(1000)
+			}
+			pulled++
+			if !yield(call) {
+				return
+			}
+		}
+	}
+	results := []Result[big.Int]{}
+	StreamMany(mc, &bind.CallOpts{Context: ctx}, calls)(func(i int, res Result[big.Int]) bool {
+		results = append(results, res)
+		return true
+	})
+	// the first chunk, then the call that didn't fit in it; the next one is offered, but refused.
+	assert.Equal(t, 12, pulled)
+	assert.Len(t, results, 11)
+	for _, res := range results {
+		assert.ErrorIs(t, res.Err(), context.Canceled)
+	}
+}
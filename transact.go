@@ -0,0 +1,136 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// The outcome of `TransactMany()`.
+type TransactResult struct {
+	Transaction *types.Transaction
+	// Nil when the transaction wasn't sent (`TransactOpts.NoSend`).
+	Receipt *types.Receipt
+	// What each call returned in the simulation made before sending the transaction.
+	Simulated []DeserializedMulticall3Result
+}
+
+/*
+ * Sends `calls` as a single aggregate3 transaction (aggregate3Value if any of them sends a value) and waits for
+ * its receipt. The calls are atomic: if any of them fails, the whole transaction reverts.
+ *
+ * The transaction is first simulated with an eth_call from `opts.From`; if any call would fail, nothing is sent
+ * and the error lists why each one failed. Unless `opts.GasLimit` is set, the gas is then estimated. `opts.Value`
+ * is ignored in favour of the calls' total value.
+ *
+ * Note that the calls are made by the multicall contract, so it's their msg.sender, not `opts.From`. Only use
+ * this for methods that don't care who calls them, or that have granted the multicall contract permission. For
+ * the same reason, calls with a `From` are rejected.
+ */
+func TransactMany(mc *MulticallClient, opts *bind.TransactOpts, calls ...RawMulticall) (*TransactResult, error) {
+	if mc.Client == nil {
+		return nil, ErrNoClient
+	}
+	if opts == nil {
+		return nil, errors.New("no transact options to send with")
+	}
+	if len(calls) == 0 {
+		return nil, errors.New("no calls to send")
+	}
+	for i, call := range calls {
+		if call.From != nil {
+			return nil, fmt.Errorf("call %d (%s) has a From, but every call is made by the multicall contract", i, call.FunctionName)
+		}
+	}
+	params := mapCollection(calls, func(call RawMulticall, _ uint64) ParamMulticall3Call3 {
+		return ParamMulticall3Call3{Target: call.Address, AllowFailure: true, Value: call.Value, CallData: call.Data}
+	})
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = callContext(mc, nil)
+	}
+
+	// simulate with failures allowed, so that every failing call is reported.
+	method, simulation, value, err := mc.packAggregate3(params)
+	if err != nil {
+		return nil, err
+	}
+	out, err := mc.callAt(&bind.CallOpts{Context: ctx}, ethereum.CallMsg{From: opts.From, To: &mc.Address, Value: value, Data: simulation})
+	if err != nil {
+		return nil, fmt.Errorf("%s simulation failed: %s", method, err)
+	}
+	res, err := mc.unpackAggregate3(method, out)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != len(calls) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(calls), len(res))
+	}
+	simulated := simulatedResults(calls, res)
+	failures := []error{}
+	for i, r := range simulated {
+		if !r.Success {
+			failures = append(failures, fmt.Errorf("call %d (%s): %w", i, calls[i].FunctionName, r.Value.(*RevertError)))
+		}
+	}
+	if len(failures) > 0 {
+		return nil, errors.Join(failures...)
+	}
+
+	for i := range params {
+		params[i].AllowFailure = false
+	}
+	_, data, _, err := mc.packAggregate3(params)
+	if err != nil {
+		return nil, err
+	}
+	txOpts := *opts
+	txOpts.Context = ctx
+	txOpts.Value = value
+	if txOpts.GasLimit == 0 {
+		gas, err := mc.Client.EstimateGas(ctx, ethereum.CallMsg{From: opts.From, To: &mc.Address, Value: value, Data: data})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %s", err.Error())
+		}
+		txOpts.GasLimit = gas
+	}
+	tx, err := mc.Contract.RawTransact(&txOpts, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %s", method, err.Error())
+	}
+	if opts.NoSend {
+		return &TransactResult{Transaction: tx, Simulated: simulated}, nil
+	}
+
+	receipt, err := bind.WaitMined(ctx, mc.Client, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for %s: %s", tx.Hash(), err.Error())
+	}
+	result := &TransactResult{Transaction: tx, Receipt: receipt, Simulated: simulated}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return result, fmt.Errorf("transaction %s reverted", tx.Hash())
+	}
+	return result, nil
+}
+
+// simulatedResults decodes the results of a simulation: values when calls succeed (if they can be deserialized),
+// and a `*RevertError` when they fail.
+func simulatedResults(calls []RawMulticall, res []Multicall3Result) []DeserializedMulticall3Result {
+	simulated := make([]DeserializedMulticall3Result, len(res))
+	for i, r := range res {
+		if !r.Success {
+			simulated[i] = DeserializedMulticall3Result{Success: false, Value: DecodeRevert(r.ReturnData)}
+			continue
+		}
+		simulated[i] = DeserializedMulticall3Result{Success: true}
+		if calls[i].Deserialize != nil && len(r.ReturnData) > 0 {
+			if val, err := calls[i].Deserialize(r.ReturnData); err == nil {
+				simulated[i].Value = val
+			}
+		}
+	}
+	return simulated
+}
@@ -0,0 +1,69 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactMany(t *testing.T) {
+	chain := newFakeChain()
+	token := fakeAddress(0xa0)
+	vault := fakeAddress(0xb0)
+	chain.contracts[token] = fakeERC20("A", 18)
+	chain.contracts[vault] = fakeDepositVault()
+	mc := newFakeClient(t, chain)
+	erc20 := mustParseABI(t, erc20TestAbi)
+	vaultAbi := mustParseABI(t, depositVaultTestAbi)
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	opts, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1))
+	assert.NoError(t, err)
+	opts.GasPrice = big.NewInt(1_000_000_000)
+	opts.Nonce = big.NewInt(0)
+
+	// the multicall contract is msg.sender, so transfers are limited by its balance.
+	transfer, err := Describe[bool](token, erc20, "transfer", fakeAddress(1), big.NewInt(100))
+	assert.NoError(t, err)
+	tooMuch, err := Describe[bool](token, erc20, "transfer", fakeAddress(1), big.NewInt(1_000_000))
+	assert.NoError(t, err)
+	deposit, err := Describe[big.Int](vault, vaultAbi, "deposit")
+	assert.NoError(t, err)
+
+	_, err = TransactMany(mc, opts, transfer.Raw(), tooMuch.Raw(), deposit.Raw())
+	assert.ErrorContains(t, err, "call 1 (transfer): call failed: ERC20: transfer amount exceeds balance")
+	assert.ErrorContains(t, err, "call 2 (deposit): call failed: nothing deposited")
+	assert.Empty(t, chain.sent)
+
+	result, err := TransactMany(mc, opts, transfer.Raw(), deposit.WithValue(big.NewInt(5)).Raw())
+	assert.NoError(t, err)
+	assert.Len(t, chain.sent, 1)
+	assert.Equal(t, types.ReceiptStatusSuccessful, result.Receipt.Status)
+	assert.Equal(t, result.Transaction.Hash(), result.Receipt.TxHash)
+	assert.Equal(t, "5", result.Transaction.Value().String())
+	assert.Equal(t, uint64(100_000), result.Transaction.Gas())
+	assert.Equal(t, mc.Address, *result.Transaction.To())
+	assert.Equal(t, true, *result.Simulated[0].Value.(*bool))
+	assert.Equal(t, "10", result.Simulated[1].Value.(*big.Int).String())
+
+	opts.Nonce = big.NewInt(1)
+	opts.NoSend = true
+	unsent, err := TransactMany(mc, opts, transfer.Raw())
+	assert.NoError(t, err)
+	assert.Nil(t, unsent.Receipt)
+	assert.Len(t, chain.sent, 1)
+
+	// neither is sent nor simulated.
+	chain.ethCalls = 0
+	_, err = TransactMany(mc, nil, transfer.Raw())
+	assert.ErrorContains(t, err, "no transact options")
+	_, err = TransactMany(mc, opts, transfer.Raw(), transfer.WithFrom(fakeAddress(1)).Raw())
+	assert.ErrorContains(t, err, "call 1 (transfer) has a From")
+	assert.Equal(t, 0, chain.ethCalls)
+	assert.Len(t, chain.sent, 1)
+}
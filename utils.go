@@ -32,7 +32,7 @@ func chunkCalls(allCalls []ParamMulticall3Call3, maxBatchSizeBytes int) [][]Para
 	currentBatch := []ParamMulticall3Call3{}
 
 	for _, call := range allCalls {
-		if (currentBatchSize + len(call.CallData)) > maxBatchSizeBytes {
+		if exceedsBatchSize(currentBatchSize, len(call.CallData), maxBatchSizeBytes) {
 			// we can't fit in this batch, so dump the current batch and start a new one
 			results = append(results, currentBatch)
 			currentBatchSize = 0
@@ -50,3 +50,8 @@ func chunkCalls(allCalls []ParamMulticall3Call3, maxBatchSizeBytes int) [][]Para
 
 	return results
 }
+
+// Whether appending a call with `callSize` bytes of calldata to the current batch would exceed maxBatchSizeBytes.
+func exceedsBatchSize(currentBatchSize int, callSize int, maxBatchSizeBytes int) bool {
+	return (currentBatchSize + callSize) > maxBatchSizeBytes
+}
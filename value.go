@@ -0,0 +1,103 @@
+package multicall
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+/*
+ * Returns a copy of the call that sends `value` wei along, to simulate a payable method such as a deposit.
+ *
+ * Batches holding calls with a value are made through Multicall3's aggregate3Value, with the summed value sent on
+ * the eth_call itself, so its sender (`CallOpts.From`, the zero address by default) must hold that much.
+ */
+func (md *MultiCallMetaData[T]) WithValue(value *big.Int) *MultiCallMetaData[T] {
+	call := *md
+	call.Value = value
+	return &call
+}
+
+func totalValue(calls []ParamMulticall3Call3) *big.Int {
+	total := new(big.Int)
+	for _, call := range calls {
+		if call.Value != nil {
+			total.Add(total, call.Value)
+		}
+	}
+	return total
+}
+
+/*
+ * packAggregate3 encodes a chunk of calls for aggregate3, or for aggregate3Value if any of them sends a value. It
+ * returns the method used and the value the eth_call has to send.
+ */
+func (mc *MulticallClient) packAggregate3(calls []ParamMulticall3Call3) (string, []byte, *big.Int, error) {
+	if mc.Dialect != DialectMulticall3 {
+		return "", nil, nil, fmt.Errorf("%s doesn't support aggregate3", mc.Dialect)
+	}
+	method := "aggregate3"
+	value := totalValue(calls)
+	if value.Sign() > 0 {
+		method = "aggregate3Value"
+		calls = mapCollection(calls, func(call ParamMulticall3Call3, _ uint64) ParamMulticall3Call3 {
+			if call.Value == nil {
+				call.Value = new(big.Int)
+			}
+			return call
+		})
+	}
+	data, err := mc.ABI.Pack(method, calls)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("error packing multicall: %s", err.Error())
+	}
+	return method, data, value, nil
+}
+
+func (mc *MulticallClient) aggregate3Value(opts *bind.CallOpts, calls []ParamMulticall3Call3) ([]Multicall3Result, error) {
+	method, data, value, err := mc.packAggregate3(calls)
+	if err != nil {
+		return nil, err
+	}
+	out, err := mc.callWithValue(opts, value, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return mc.unpackAggregate3(method, out)
+}
+
+func (mc *MulticallClient) unpackAggregate3(method string, out []byte) ([]Multicall3Result, error) {
+	res, err := mc.ABI.Unpack(method, out)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %s", method, err)
+	}
+	return *abi.ConvertType(res[0], new([]Multicall3Result)).(*[]Multicall3Result), nil
+}
+
+// callWithValue makes a raw eth_call of the multicall contract that sends `value` along with it.
+func (mc *MulticallClient) callWithValue(opts *bind.CallOpts, value *big.Int, data []byte) ([]byte, error) {
+	if mc.Client == nil {
+		return nil, ErrNoClient
+	}
+	if opts == nil {
+		opts = &bind.CallOpts{}
+	}
+	return mc.callAt(opts, ethereum.CallMsg{From: opts.From, To: &mc.Address, Value: value, Data: data})
+}
+
+// callAt makes a raw eth_call at the block selected by `opts`.
+func (mc *MulticallClient) callAt(opts *bind.CallOpts, msg ethereum.CallMsg) ([]byte, error) {
+	ctx := callContext(mc, opts)
+	switch {
+	case opts.BlockHash != (common.Hash{}):
+		return mc.Client.CallContractAtHash(ctx, msg, opts.BlockHash)
+	case opts.Pending:
+		return mc.Client.PendingCallContract(ctx, msg)
+	default:
+		return mc.Client.CallContract(ctx, msg, opts.BlockNumber)
+	}
+}
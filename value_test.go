@@ -0,0 +1,117 @@
+package multicall
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+)
+
+const depositVaultTestAbi = `[
+	{"type":"function","name":"deposit","stateMutability":"payable","inputs":[],"outputs":[{"name":"shares","type":"uint256"}]},
+	{"type":"function","name":"sharePrice","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+// fakeDepositVault mints 2 shares per wei deposited, and rejects empty deposits.
+func fakeDepositVault() fakeContract {
+	parsed := panicIfError(abi.JSON(strings.NewReader(depositVaultTestAbi)))
+	return abiContract(parsed, map[string]fakeMethod{
+		"deposit": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			if ctx.value == nil || ctx.value.Sign() == 0 {
+				return nil, revertWithReason("nothing deposited")
+			}
+			return []interface{}{new(big.Int).Mul(ctx.value, big.NewInt(2))}, nil
+		},
+		"sharePrice": func(ctx fakeCallContext, args []interface{}) ([]interface{}, error) {
+			return []interface{}{big.NewInt(2)}, nil
+		},
+	})
+}
+
+func TestValueCalls(t *testing.T) {
+	chain := newFakeChain()
+	vault := fakeAddress(0xa0)
+	chain.contracts[vault] = fakeDepositVault()
+	mc := newFakeClient(t, chain)
+	vaultAbi := mustParseABI(t, depositVaultTestAbi)
+
+	deposit, err := Describe[big.Int](vault, vaultAbi, "deposit")
+	assert.NoError(t, err)
+	results, err := DoManyResults(mc, deposit.WithValue(big.NewInt(100)), deposit, deposit.WithValue(big.NewInt(7)))
+	assert.NoError(t, err)
+	assert.Equal(t, "200", results[0].Value.String())
+	assert.ErrorContains(t, results[1].Err(), "nothing deposited")
+	assert.Equal(t, "14", results[2].Value.String())
+	assert.Nil(t, deposit.Value)
+
+	// calls without a value still go through aggregate3, which sends none.
+	price, err := Describe[big.Int](vault, vaultAbi, "sharePrice")
+	assert.NoError(t, err)
+	values, err := DoManyValues(mc, price, price)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", values[0].String())
+
+	mapped := Map(deposit.WithValue(big.NewInt(5)), func(shares *big.Int) (string, error) {
+		return shares.String() + " shares", nil
+	})
+	zipped, err := Zip(mc, mapped, deposit.WithValue(big.NewInt(3)))
+	assert.NoError(t, err)
+	assert.Equal(t, "8", zipped.Value.String())
+	pair, sharePrice, err := Do(mc, zipped, price)
+	assert.NoError(t, err)
+	assert.Equal(t, "10 shares", pair.First)
+	assert.Equal(t, "6", pair.Second.String())
+	assert.Equal(t, "2", sharePrice.String())
+
+	_, err = DoManyAttested(mc, deposit.WithValue(big.NewInt(1)))
+	assert.Error(t, err)
+}
+
+func TestValueCalls_Serialized(t *testing.T) {
+	chain := newFakeChain()
+	vault := fakeAddress(0xb0)
+	chain.contracts[vault] = fakeDepositVault()
+	mc := newFakeClient(t, chain)
+
+	deposit, err := Describe[big.Int](vault, mustParseABI(t, depositVaultTestAbi), "deposit")
+	assert.NoError(t, err)
+	deposit = deposit.WithValue(big.NewInt(7))
+
+	descriptor, err := NewCallDescriptor(deposit, nil)
+	assert.NoError(t, err)
+	encoded, err := descriptor.Marshal()
+	assert.NoError(t, err)
+	var received CallDescriptor
+	assert.NoError(t, received.Unmarshal(encoded))
+	typed, err := CompileAs[big.Int](&received)
+	assert.NoError(t, err)
+	dynamic, err := received.Compile()
+	assert.NoError(t, err)
+	assert.Equal(t, "7", typed.Value.String())
+	assert.Equal(t, "7", dynamic.Value.String())
+
+	encoded, err = json.Marshal(deposit)
+	assert.NoError(t, err)
+	var decoded MultiCallMetaData[big.Int]
+	assert.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, "7", decoded.Value.String())
+
+	raw := &MultiCallMetaData[[]byte]{Address: vault, Data: deposit.Data, FunctionName: "deposit", Value: big.NewInt(7)}
+	encoded, err = json.Marshal(raw)
+	assert.NoError(t, err)
+	var decodedRaw MultiCallMetaData[[]byte]
+	assert.NoError(t, json.Unmarshal(encoded, &decodedRaw))
+	assert.Equal(t, "7", decodedRaw.Value.String())
+
+	// each still sends its value when it's made.
+	values, err := DoManyValues(mc, typed, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, "14", values[0].String())
+	assert.Equal(t, "14", values[1].String())
+	named, err := DoManyValues(mc, dynamic)
+	assert.NoError(t, err)
+	assert.Equal(t, "14", named[0][0].Value.(*big.Int).String())
+}